@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/scorum/bitshares-go/caller"
 	"github.com/scorum/bitshares-go/types"
@@ -15,8 +16,8 @@ func NewAPI(id caller.APIID, caller caller.Caller) *API {
 	return &API{id: id, caller: caller}
 }
 
-func (api *API) call(method string, args []interface{}, reply interface{}) error {
-	return api.caller.Call(api.id, method, args, reply)
+func (api *API) call(ctx context.Context, method string, args []interface{}, reply interface{}) error {
+	return caller.CallContext(ctx, api.caller, api.id, method, args, reply)
 }
 
 func (api *API) setCallback(method string, callback func(raw json.RawMessage)) error {
@@ -24,22 +25,37 @@ func (api *API) setCallback(method string, callback func(raw json.RawMessage)) e
 }
 
 func (api *API) GetChainID() (*string, error) {
+	return api.GetChainIDContext(context.Background())
+}
+
+// GetChainIDContext is GetChainID with a context
+func (api *API) GetChainIDContext(ctx context.Context) (*string, error) {
 	var resp string
-	err := api.call("get_chain_id", caller.EmptyParams, &resp)
+	err := api.call(ctx, "get_chain_id", caller.EmptyParams, &resp)
 	return &resp, err
 }
 
 // GetConfig retrieves compile-time constants
 func (api *API) GetConfig() (*Config, error) {
+	return api.GetConfigContext(context.Background())
+}
+
+// GetConfigContext is GetConfig with a context
+func (api *API) GetConfigContext(ctx context.Context) (*Config, error) {
 	var config Config
-	err := api.call("get_config", caller.EmptyParams, &config)
+	err := api.call(ctx, "get_config", caller.EmptyParams, &config)
 	return &config, err
 }
 
 // GetTransaction used to fetch an individual transaction
 func (api *API) GetTransaction(blockNum uint32, trxInBlock uint32) (*types.Transaction, error) {
+	return api.GetTransactionContext(context.Background(), blockNum, trxInBlock)
+}
+
+// GetTransactionContext is GetTransaction with a context
+func (api *API) GetTransactionContext(ctx context.Context, blockNum uint32, trxInBlock uint32) (*types.Transaction, error) {
 	var resp types.Transaction
-	err := api.call("get_transaction", []interface{}{blockNum, trxInBlock}, &resp)
+	err := api.call(ctx, "get_transaction", []interface{}{blockNum, trxInBlock}, &resp)
 	return &resp, err
 }
 
@@ -48,22 +64,37 @@ func (api *API) GetTransaction(blockNum uint32, trxInBlock uint32) (*types.Trans
 // it will return NULL if it is not known. Just because it is not known does not mean
 // it wasn’t included in the blockchain.
 func (api *API) GetRecentTransactionByID(transactionID uint32) (*types.Transaction, error) {
+	return api.GetRecentTransactionByIDContext(context.Background(), transactionID)
+}
+
+// GetRecentTransactionByIDContext is GetRecentTransactionByID with a context
+func (api *API) GetRecentTransactionByIDContext(ctx context.Context, transactionID uint32) (*types.Transaction, error) {
 	var resp types.Transaction
-	err := api.call("get_recent_transaction_by_id", []interface{}{transactionID}, &resp)
+	err := api.call(ctx, "get_recent_transaction_by_id", []interface{}{transactionID}, &resp)
 	return &resp, err
 }
 
 // GetDynamicGlobalProperties retrieves the current global_property_object
 func (api *API) GetDynamicGlobalProperties() (*DynamicGlobalProperties, error) {
+	return api.GetDynamicGlobalPropertiesContext(context.Background())
+}
+
+// GetDynamicGlobalPropertiesContext is GetDynamicGlobalProperties with a context
+func (api *API) GetDynamicGlobalPropertiesContext(ctx context.Context) (*DynamicGlobalProperties, error) {
 	var resp DynamicGlobalProperties
-	err := api.call("get_dynamic_global_properties", caller.EmptyParams, &resp)
+	err := api.call(ctx, "get_dynamic_global_properties", caller.EmptyParams, &resp)
 	return &resp, err
 }
 
 // LookupAssetSymbols get assets corresponding to the provided symbols or IDs
 func (api *API) LookupAssetSymbols(symbols ...string) ([]*Asset, error) {
+	return api.LookupAssetSymbolsContext(context.Background(), symbols...)
+}
+
+// LookupAssetSymbolsContext is LookupAssetSymbols with a context
+func (api *API) LookupAssetSymbolsContext(ctx context.Context, symbols ...string) ([]*Asset, error) {
 	var resp []*Asset
-	err := api.call("lookup_asset_symbols", []interface{}{symbols}, &resp)
+	err := api.call(ctx, "lookup_asset_symbols", []interface{}{symbols}, &resp)
 	return resp, err
 }
 
@@ -72,44 +103,74 @@ func (api *API) LookupAssetSymbols(symbols ...string) ([]*Asset, error) {
 // For the sell orders LimitOrder.SellPrice.Base = the given base
 // For the buy orders LimitOrder.SellPrice.Base = the given quote
 func (api *API) GetLimitOrders(base, quote types.ObjectID, limit uint32) ([]*LimitOrder, error) {
+	return api.GetLimitOrdersContext(context.Background(), base, quote, limit)
+}
+
+// GetLimitOrdersContext is GetLimitOrders with a context
+func (api *API) GetLimitOrdersContext(ctx context.Context, base, quote types.ObjectID, limit uint32) ([]*LimitOrder, error) {
 	var resp []*LimitOrder
-	err := api.call("get_limit_orders", []interface{}{base.String(), quote.String(), limit}, &resp)
+	err := api.call(ctx, "get_limit_orders", []interface{}{base.String(), quote.String(), limit}, &resp)
 	return resp, err
 }
 
 // GetBlockHeader returns block header by the given block number
 func (api *API) GetBlockHeader(blockNum uint32) (*BlockHeader, error) {
+	return api.GetBlockHeaderContext(context.Background(), blockNum)
+}
+
+// GetBlockHeaderContext is GetBlockHeader with a context
+func (api *API) GetBlockHeaderContext(ctx context.Context, blockNum uint32) (*BlockHeader, error) {
 	var resp BlockHeader
-	err := api.call("get_block_header", []interface{}{blockNum}, &resp)
+	err := api.call(ctx, "get_block_header", []interface{}{blockNum}, &resp)
 	return &resp, err
 }
 
 // GetBlock return a block by the given block number
 func (api *API) GetBlock(blockNum uint32) (*Block, error) {
+	return api.GetBlockContext(context.Background(), blockNum)
+}
+
+// GetBlockContext is GetBlock with a context
+func (api *API) GetBlockContext(ctx context.Context, blockNum uint32) (*Block, error) {
 	var resp Block
-	err := api.call("get_block", []interface{}{blockNum}, &resp)
+	err := api.call(ctx, "get_block", []interface{}{blockNum}, &resp)
 	return &resp, err
 }
 
 // GetBlock return a block by the given block number
 func (api *API) GetObjects(assets ...types.ObjectID) ([]json.RawMessage, error) {
+	return api.GetObjectsContext(context.Background(), assets...)
+}
+
+// GetObjectsContext is GetObjects with a context
+func (api *API) GetObjectsContext(ctx context.Context, assets ...types.ObjectID) ([]json.RawMessage, error) {
 	var resp []json.RawMessage
-	err := api.call("get_objects", []interface{}{objectsToParams(assets)}, &resp)
+	err := api.call(ctx, "get_objects", []interface{}{objectsToParams(assets)}, &resp)
 	return resp, err
 }
 
 // GetTicker returns the ticker for the market assetA:assetB (past 24 hours)
 func (api *API) GetTicker(base, quote types.ObjectID) (*MarketTicker, error) {
+	return api.GetTickerContext(context.Background(), base, quote)
+}
+
+// GetTickerContext is GetTicker with a context
+func (api *API) GetTickerContext(ctx context.Context, base, quote types.ObjectID) (*MarketTicker, error) {
 	var resp MarketTicker
-	err := api.call("get_ticker", []interface{}{base.String(), quote.String()}, &resp)
+	err := api.call(ctx, "get_ticker", []interface{}{base.String(), quote.String()}, &resp)
 	return &resp, err
 }
 
 // GetAccountBalances
 // Get an account’s balances in various assets.
 func (api *API) GetAccountBalances(accountID types.ObjectID, assets ...types.ObjectID) ([]*types.AssetAmount, error) {
+	return api.GetAccountBalancesContext(context.Background(), accountID, assets...)
+}
+
+// GetAccountBalancesContext is GetAccountBalances with a context
+func (api *API) GetAccountBalancesContext(ctx context.Context, accountID types.ObjectID, assets ...types.ObjectID) ([]*types.AssetAmount, error) {
 	var resp []*types.AssetAmount
-	err := api.call("get_account_balances", []interface{}{accountID.String(), objectsToParams(assets)}, &resp)
+	err := api.call(ctx, "get_account_balances", []interface{}{accountID.String(), objectsToParams(assets)}, &resp)
 	return resp, err
 }
 
@@ -123,8 +184,13 @@ func objectsToParams(objs []types.ObjectID) []string {
 
 // Semantically equivalent to get_account_balances, but takes a name instead of an ID.
 func (api *API) GetNamedAccountBalances(account string, assets ...types.ObjectID) ([]*types.AssetAmount, error) {
+	return api.GetNamedAccountBalancesContext(context.Background(), account, assets...)
+}
+
+// GetNamedAccountBalancesContext is GetNamedAccountBalances with a context
+func (api *API) GetNamedAccountBalancesContext(ctx context.Context, account string, assets ...types.ObjectID) ([]*types.AssetAmount, error) {
 	var resp []*types.AssetAmount
-	err := api.call("get_named_account_balances", []interface{}{account, objectsToParams(assets)}, &resp)
+	err := api.call(ctx, "get_named_account_balances", []interface{}{account, objectsToParams(assets)}, &resp)
 	return resp, err
 }
 
@@ -132,8 +198,13 @@ func (api *API) GetNamedAccountBalances(account string, assets ...types.ObjectID
 // lower_bound_name: Lower bound of the first name to return
 // limit: Maximum number of results to return must not exceed 1000
 func (api *API) LookupAccounts(lowerBoundName string, limit uint16) (AccountsMap, error) {
+	return api.LookupAccountsContext(context.Background(), lowerBoundName, limit)
+}
+
+// LookupAccountsContext is LookupAccounts with a context
+func (api *API) LookupAccountsContext(ctx context.Context, lowerBoundName string, limit uint16) (AccountsMap, error) {
 	var resp AccountsMap
-	err := api.call("lookup_accounts", []interface{}{lowerBoundName, limit}, &resp)
+	err := api.call(ctx, "lookup_accounts", []interface{}{lowerBoundName, limit}, &resp)
 	return resp, err
 }
 
@@ -153,11 +224,21 @@ func (api *API) SetBlockAppliedCallback(notice func(blockID string, err error))
 
 // CancelAllSubscriptions cancel all subscriptions
 func (api *API) CancelAllSubscriptions() error {
-	return api.call("cancel_all_subscriptions", caller.EmptyParams, nil)
+	return api.CancelAllSubscriptionsContext(context.Background())
+}
+
+// CancelAllSubscriptionsContext is CancelAllSubscriptions with a context
+func (api *API) CancelAllSubscriptionsContext(ctx context.Context) error {
+	return api.call(ctx, "cancel_all_subscriptions", caller.EmptyParams, nil)
 }
 
 // GetRequiredFee fetchs fee for operations
 func (api *API) GetRequiredFee(ops []types.Operation, assetID string) ([]types.AssetAmount, error) {
+	return api.GetRequiredFeeContext(context.Background(), ops, assetID)
+}
+
+// GetRequiredFeeContext is GetRequiredFee with a context
+func (api *API) GetRequiredFeeContext(ctx context.Context, ops []types.Operation, assetID string) ([]types.AssetAmount, error) {
 	var resp []types.AssetAmount
 
 	opsJSON := []interface{}{}
@@ -172,6 +253,6 @@ func (api *API) GetRequiredFee(ops []types.Operation, assetID string) ([]types.A
 		opsJSON = append(opsJSON, opArr)
 	}
 
-	err := api.call("get_required_fees", []interface{}{opsJSON, assetID}, &resp)
+	err := api.call(ctx, "get_required_fees", []interface{}{opsJSON, assetID}, &resp)
 	return resp, err
 }
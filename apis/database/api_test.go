@@ -1,8 +1,8 @@
 package database
 
 import (
-	"encoding/json"
 	"github.com/scorum/bitshares-go/apis/login"
+	"github.com/scorum/bitshares-go/nodetest"
 	"github.com/scorum/bitshares-go/transport/websocket"
 	"github.com/scorum/bitshares-go/types"
 	"github.com/stretchr/testify/require"
@@ -10,11 +10,14 @@ import (
 	"time"
 )
 
-const url = "wss://bitshares.openledger.info/ws"
-
 func getAPI(t *testing.T) *API {
-	transport, err := websocket.NewTransport(url)
+	return newAPI(t, newServer(t))
+}
+
+func newAPI(t *testing.T, server *nodetest.Server) *API {
+	transport, err := websocket.NewTransport(server.URL)
 	require.NoError(t, err)
+	t.Cleanup(func() { transport.Close() })
 
 	// request access to the database api
 	databaseAPIID, err := login.NewAPI(transport).Database()
@@ -184,17 +187,24 @@ func TestGetConfig(t *testing.T) {
 }
 
 func TestSetBlockAppliedCallback(t *testing.T) {
-	databaseAPI := getAPI(t)
+	server := newServer(t)
+	databaseAPI := newAPI(t, server)
 
-	var called bool
+	called := make(chan string, 1)
 	err := databaseAPI.SetBlockAppliedCallback(func(blockID string, err error) {
 		t.Log("block:", blockID)
 		require.NoError(t, err)
-		called = true
+		called <- blockID
 	})
 	require.NoError(t, err)
-	time.Sleep(5 * time.Second)
-	require.True(t, called)
+
+	require.NoError(t, server.Notify("set_block_applied_callback", []string{"01a3c0143c55a7bbb6f5af30c2bd0a9a1e5a0f21"}))
+	select {
+	case blockID := <-called:
+		require.Equal(t, "01a3c0143c55a7bbb6f5af30c2bd0a9a1e5a0f21", blockID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback has not been called")
+	}
 
 	require.NoError(t, databaseAPI.CancelAllSubscriptions())
 }
@@ -213,7 +223,6 @@ func TestGetRequiredFee(t *testing.T) {
 		Amount:  1000000,
 		AssetID: types.MustParseObjectID("1.3.0"),
 	}
-	op.Extensions = []json.RawMessage{}
 
 	res, err := databaseAPI.GetRequiredFee([]types.Operation{&op}, "1.3.0")
 	require.NoError(t, err)
@@ -0,0 +1,219 @@
+package database
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/nodetest"
+)
+
+const chainID = "4018d7844c78f6a6c41c6a552b898022310fc5dec06da467ee7905a8dad512c8"
+
+var assets = map[string]string{
+	"OPEN.BTC": `{"id":"1.3.861","symbol":"OPEN.BTC","precision":8,"issuer":"1.2.96393","dynamic_asset_data_id":"2.3.861"}`,
+	"OPEN.SCR": `{"id":"1.3.3232","symbol":"OPEN.SCR","precision":6,"issuer":"1.2.96393","dynamic_asset_data_id":"2.3.3232"}`,
+	"USD":      `{"id":"1.3.121","symbol":"USD","precision":4,"issuer":"1.2.0","dynamic_asset_data_id":"2.3.121"}`,
+}
+
+var balances = map[string]string{
+	"1.3.0":    `{"amount":"14450706212","asset_id":"1.3.0"}`,
+	"1.3.121":  `{"amount":1100,"asset_id":"1.3.121"}`,
+	"1.3.3232": `{"amount":700000,"asset_id":"1.3.3232"}`,
+}
+
+var accounts = map[string]string{
+	"init0":      "1.2.100",
+	"init1":      "1.2.101",
+	"megaherz1":  "1.2.900546",
+	"openledger": "1.2.96393",
+}
+
+const transaction = `{
+  "ref_block_num": 46869,
+  "ref_block_prefix": 3461233562,
+  "expiration": "2018-06-06T10:06:30",
+  "operations": [[0, {
+    "fee": {"amount": 86869, "asset_id": "1.3.0"},
+    "from": "1.2.900546",
+    "to": "1.2.96393",
+    "amount": {"amount": 100000, "asset_id": "1.3.0"},
+    "extensions": []
+  }]],
+  "extensions": [],
+  "signatures": ["1f1b8ec4b1ac93d2ee8d4d6f2e9e8b8a1d4f9b9b5a2ab1a1d1ef1e5f1c8c1d2e3d4c6f7a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a5b6c7d8e9f0a"]
+}`
+
+// newServer starts a node emulating the database_api
+func newServer(t *testing.T) *nodetest.Server {
+	server := nodetest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.Respond("database", "get_chain_id", chainID)
+	server.RespondJSON("database", "get_config", `{
+		"GRAPHENE_SYMBOL": "BTS",
+		"GRAPHENE_ADDRESS_PREFIX": "BTS",
+		"GRAPHENE_MIN_ACCOUNT_NAME_LENGTH": 1,
+		"GRAPHENE_MAX_ACCOUNT_NAME_LENGTH": 63,
+		"GRAPHENE_MIN_ASSET_SYMBOL_LENGTH": 3,
+		"GRAPHENE_MAX_ASSET_SYMBOL_LENGTH": 16,
+		"GRAPHENE_MAX_SHARE_SUPPLY": "1000000000000000"
+	}`)
+	server.RespondJSON("database", "get_dynamic_global_properties", `{
+		"id": "2.1.0",
+		"head_block_number": 27508500,
+		"head_block_id": "01a3c0143c55a7bbb6f5af30c2bd0a9a1e5a0f21",
+		"time": "2018-06-06T10:00:00",
+		"current_witness": "1.6.22",
+		"next_maintenance_time": "2018-06-06T11:00:00",
+		"last_budget_time": "2018-06-06T10:00:00",
+		"accounts_registered_this_interval": 3,
+		"dynamic_flags": 0,
+		"recent_slots_filled": "340282366920938463463374607431768211455",
+		"last_irreversible_block_num": 27508480,
+		"current_aslot": 27707270,
+		"witness_budget": 61900000,
+		"recently_missed_count": 0
+	}`)
+	server.RespondJSON("database", "get_block_header", `{
+		"transaction_merkle_root": "0000000000000000000000000000000000000000",
+		"previous": "0000001800b4cdd93df0a3a3dd5ea0b3b5ec3e3d",
+		"timestamp": "2015-10-13T14:12:45",
+		"witness": "1.6.6",
+		"extensions": []
+	}`)
+	server.RespondJSON("database", "get_block", `{
+		"transaction_merkle_root": "1ab2c3d4e5f60718293a4b5c6d7e8f9012345678",
+		"previous": "019997140b5a3f3e8a4f0d9fe1fd9d1b1c8c2e8a",
+		"timestamp": "2018-06-06T10:06:00",
+		"witness": "1.6.45",
+		"extensions": [],
+		"witness_signature": "1f3b9c",
+		"transactions": [`+transaction+`, `+transaction+`]
+	}`)
+	server.Handle("database", "get_transaction", func(params []json.RawMessage) (interface{}, error) {
+		var blockNum, trxInBlock uint32
+		if err := nodetest.Unmarshal(params, &blockNum, &trxInBlock); err != nil {
+			return nil, err
+		}
+		if trxInBlock >= 2 {
+			return nil, errors.New("Assert Exception: opt_block->transactions.size() > trx_num")
+		}
+		return json.RawMessage(transaction), nil
+	})
+	server.RespondJSON("database", "get_recent_transaction_by_id", transaction)
+	server.Handle("database", "lookup_asset_symbols", func(params []json.RawMessage) (interface{}, error) {
+		var symbols []string
+		if err := nodetest.Unmarshal(params, &symbols); err != nil {
+			return nil, err
+		}
+		result := make([]json.RawMessage, len(symbols))
+		for i, s := range symbols {
+			result[i] = json.RawMessage("null")
+			if asset, ok := assets[s]; ok {
+				result[i] = json.RawMessage(asset)
+			}
+		}
+		return result, nil
+	})
+	server.Handle("database", "get_limit_orders", func(params []json.RawMessage) (interface{}, error) {
+		var base, quote string
+		var limit uint32
+		if err := nodetest.Unmarshal(params, &base, &quote, &limit); err != nil {
+			return nil, err
+		}
+		return json.RawMessage(`[{
+			"id": "1.7.95227648",
+			"expiration": "2023-06-06T10:00:00",
+			"seller": "1.2.900546",
+			"for_sale": 12000,
+			"deferred_fee": 0,
+			"sell_price": {
+				"base": {"amount": 12000, "asset_id": "` + base + `"},
+				"quote": {"amount": "1000000000", "asset_id": "` + quote + `"}
+			}
+		}]`), nil
+	})
+	server.Handle("database", "get_ticker", func(params []json.RawMessage) (interface{}, error) {
+		var base, quote string
+		if err := nodetest.Unmarshal(params, &base, &quote); err != nil {
+			return nil, err
+		}
+		return json.RawMessage(`{
+			"time": "2018-06-06T10:00:00",
+			"base": "` + base + `",
+			"quote": "` + quote + `",
+			"latest": "0.00001220",
+			"lowest_ask": "0.00001235",
+			"highest_bid": "0.00001200",
+			"percent_change": "1.25",
+			"base_volume": "0.0123",
+			"quote_volume": "1000.5"
+		}`), nil
+	})
+	accountBalances := func(assetIDs []string) interface{} {
+		var result []json.RawMessage
+		if len(assetIDs) == 0 {
+			for _, b := range balances {
+				result = append(result, json.RawMessage(b))
+			}
+		}
+		for _, id := range assetIDs {
+			if b, ok := balances[id]; ok {
+				result = append(result, json.RawMessage(b))
+			}
+		}
+		return result
+	}
+	server.Handle("database", "get_account_balances", func(params []json.RawMessage) (interface{}, error) {
+		var account string
+		var assetIDs []string
+		if err := nodetest.Unmarshal(params, &account, &assetIDs); err != nil {
+			return nil, err
+		}
+		return accountBalances(assetIDs), nil
+	})
+	server.Handle("database", "get_named_account_balances", func(params []json.RawMessage) (interface{}, error) {
+		var account string
+		var assetIDs []string
+		if err := nodetest.Unmarshal(params, &account, &assetIDs); err != nil {
+			return nil, err
+		}
+		if _, ok := accounts[account]; !ok {
+			return nil, errors.New("Assert Exception: account: no such account")
+		}
+		return accountBalances(assetIDs), nil
+	})
+	server.Handle("database", "lookup_accounts", func(params []json.RawMessage) (interface{}, error) {
+		var lowerBound string
+		var limit uint32
+		if err := nodetest.Unmarshal(params, &lowerBound, &limit); err != nil {
+			return nil, err
+		}
+		if limit > 1000 {
+			return nil, errors.New("Assert Exception: limit <= 1000")
+		}
+
+		var names []string
+		for name := range accounts {
+			if name >= lowerBound {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		result := [][]string{}
+		for _, name := range names {
+			if uint32(len(result)) == limit {
+				break
+			}
+			result = append(result, []string{name, accounts[name]})
+		}
+		return result, nil
+	})
+	server.RespondJSON("database", "get_required_fees", `[{"amount": 86869, "asset_id": "1.3.0"}]`)
+	server.Respond("database", "cancel_all_subscriptions", nil)
+
+	return server
+}
@@ -1,6 +1,7 @@
 package history
 
 import (
+	"context"
 	"github.com/scorum/bitshares-go/caller"
 	"github.com/scorum/bitshares-go/types"
 )
@@ -14,29 +15,44 @@ func NewAPI(id caller.APIID, caller caller.Caller) *API {
 	return &API{id: id, caller: caller}
 }
 
-func (api *API) call(method string, args []interface{}, reply interface{}) error {
-	return api.caller.Call(api.id, method, args, reply)
+func (api *API) call(ctx context.Context, method string, args []interface{}, reply interface{}) error {
+	return caller.CallContext(ctx, api.caller, api.id, method, args, reply)
 }
 
 // GetMarketHistory returns market history base/quote (candlesticks) for the given period
 func (api *API) GetMarketHistory(base, quote types.ObjectID, bucketSeconds uint32, start, end types.Time) ([]*Bucket, error) {
+	return api.GetMarketHistoryContext(context.Background(), base, quote, bucketSeconds, start, end)
+}
+
+// GetMarketHistoryContext is GetMarketHistory with a context
+func (api *API) GetMarketHistoryContext(ctx context.Context, base, quote types.ObjectID, bucketSeconds uint32, start, end types.Time) ([]*Bucket, error) {
 	var resp []*Bucket
-	err := api.call("get_market_history", []interface{}{base.String(), quote.String(), bucketSeconds, start, end}, &resp)
+	err := api.call(ctx, "get_market_history", []interface{}{base.String(), quote.String(), bucketSeconds, start, end}, &resp)
 	return resp, err
 }
 
 // GetMarketHistoryBuckets returns a list of buckets that can be passed to
 // `GetMarketHistory` as the `bucketSeconds` argument
 func (api *API) GetMarketHistoryBuckets() ([]uint32, error) {
+	return api.GetMarketHistoryBucketsContext(context.Background())
+}
+
+// GetMarketHistoryBucketsContext is GetMarketHistoryBuckets with a context
+func (api *API) GetMarketHistoryBucketsContext(ctx context.Context) ([]uint32, error) {
 	var resp []uint32
-	err := api.call("get_market_history_buckets", caller.EmptyParams, &resp)
+	err := api.call(ctx, "get_market_history_buckets", caller.EmptyParams, &resp)
 	return resp, err
 }
 
 // GetFillOrderHistory returns filled orders
 func (api *API) GetFillOrderHistory(base, quote types.ObjectID, limit uint32) ([]*OrderHistory, error) {
+	return api.GetFillOrderHistoryContext(context.Background(), base, quote, limit)
+}
+
+// GetFillOrderHistoryContext is GetFillOrderHistory with a context
+func (api *API) GetFillOrderHistoryContext(ctx context.Context, base, quote types.ObjectID, limit uint32) ([]*OrderHistory, error) {
 	var resp []*OrderHistory
-	err := api.call("get_fill_order_history", []interface{}{base.String(), quote.String(), limit}, &resp)
+	err := api.call(ctx, "get_fill_order_history", []interface{}{base.String(), quote.String(), limit}, &resp)
 	return resp, err
 }
 
@@ -46,7 +62,12 @@ func (api *API) GetFillOrderHistory(base, quote types.ObjectID, limit uint32) ([
 // limit: Maximum number of operations to retrieve (must not exceed 100)
 // start: ID of the most recent operation to retrieve
 func (api *API) GetAccountHistory(account, stop types.ObjectID, limit int, start types.ObjectID) ([]*OperationHistory, error) {
+	return api.GetAccountHistoryContext(context.Background(), account, stop, limit, start)
+}
+
+// GetAccountHistoryContext is GetAccountHistory with a context
+func (api *API) GetAccountHistoryContext(ctx context.Context, account, stop types.ObjectID, limit int, start types.ObjectID) ([]*OperationHistory, error) {
 	var history []*OperationHistory
-	err := api.call("get_account_history", []interface{}{account.String(), stop.String(), limit, start.String()}, &history)
+	err := api.call(ctx, "get_account_history", []interface{}{account.String(), stop.String(), limit, start.String()}, &history)
 	return history, err
 }
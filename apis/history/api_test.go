@@ -10,10 +10,8 @@ import (
 	"time"
 )
 
-const url = "wss://api-ru.bts.blckchnd.com"
-
 func TestGetMarketHistory(t *testing.T) {
-	transport, err := websocket.NewTransport(url)
+	transport, err := websocket.NewTransport(newServer(t).URL)
 	require.NoError(t, err)
 	defer transport.Close()
 
@@ -46,7 +44,7 @@ func TestGetMarketHistory(t *testing.T) {
 }
 
 func TestGetMarketHistoryBuckets(t *testing.T) {
-	transport, err := websocket.NewTransport(url)
+	transport, err := websocket.NewTransport(newServer(t).URL)
 	require.NoError(t, err)
 	defer transport.Close()
 
@@ -66,7 +64,7 @@ func TestGetMarketHistoryBuckets(t *testing.T) {
 }
 
 func TestGetFillOrderHistory(t *testing.T) {
-	transport, err := websocket.NewTransport(url)
+	transport, err := websocket.NewTransport(newServer(t).URL)
 	require.NoError(t, err)
 	defer transport.Close()
 
@@ -93,7 +91,7 @@ func TestGetFillOrderHistory(t *testing.T) {
 }
 
 func TestAccountHistory(t *testing.T) {
-	transport, err := websocket.NewTransport(url)
+	transport, err := websocket.NewTransport(newServer(t).URL)
 	require.NoError(t, err)
 	defer transport.Close()
 
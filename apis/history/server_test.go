@@ -0,0 +1,76 @@
+package history
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/scorum/bitshares-go/nodetest"
+)
+
+// newServer starts a node emulating the history_api
+func newServer(t *testing.T) *nodetest.Server {
+	server := nodetest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.RespondJSON("database", "lookup_asset_symbols", `[
+		{"id":"1.3.3232","symbol":"OPEN.SCR","precision":6,"issuer":"1.2.96393","dynamic_asset_data_id":"2.3.3232"},
+		{"id":"1.3.121","symbol":"USD","precision":4,"issuer":"1.2.0","dynamic_asset_data_id":"2.3.121"}
+	]`)
+	server.Respond("history", "get_market_history_buckets", []uint32{15, 60, 300, 3600, 86400})
+	server.Handle("history", "get_market_history", func(params []json.RawMessage) (interface{}, error) {
+		var base, quote string
+		var seconds uint32
+		if err := nodetest.Unmarshal(params, &base, &quote, &seconds); err != nil {
+			return nil, err
+		}
+		return json.RawMessage(`[{
+			"id": "5.1.8126578",
+			"key": {"base": "` + base + `", "quote": "` + quote + `", "seconds": 60, "open": "2018-06-06T10:00:00"},
+			"high_base": 1200, "high_quote": "1000000",
+			"low_base": 1100, "low_quote": "1000000",
+			"open_base": 1150, "open_quote": "1000000",
+			"close_base": 1190, "close_quote": "1000000",
+			"base_volume": 4690, "quote_volume": "4000000"
+		}]`), nil
+	})
+	server.Handle("history", "get_fill_order_history", func(params []json.RawMessage) (interface{}, error) {
+		var base, quote string
+		if err := nodetest.Unmarshal(params, &base, &quote); err != nil {
+			return nil, err
+		}
+		return json.RawMessage(`[{
+			"id": "0.0.0",
+			"key": {"base": "` + base + `", "quote": "` + quote + `", "sequence": -1453},
+			"time": "2018-06-06T10:00:00",
+			"op": {
+				"fee": {"amount": 0, "asset_id": "` + quote + `"},
+				"order_id": "1.7.95227648",
+				"account_id": "1.2.900546",
+				"pays": {"amount": 12000, "asset_id": "` + base + `"},
+				"receives": {"amount": "1000000000", "asset_id": "` + quote + `"},
+				"fill_price": {
+					"base": {"amount": 12000, "asset_id": "` + base + `"},
+					"quote": {"amount": "1000000000", "asset_id": "` + quote + `"}
+				},
+				"is_maker": true
+			}
+		}]`), nil
+	})
+	server.RespondJSON("history", "get_account_history", `[{
+		"id": "1.11.265544436",
+		"op": [0, {
+			"fee": {"amount": 86869, "asset_id": "1.3.0"},
+			"from": "1.2.900546",
+			"to": "1.2.96393",
+			"amount": {"amount": 100000, "asset_id": "1.3.0"},
+			"extensions": []
+		}],
+		"result": [0, {}],
+		"block_num": 27508480,
+		"trx_in_block": 3,
+		"op_in_trx": 0,
+		"virtual_op": 47114
+	}]`)
+
+	return server
+}
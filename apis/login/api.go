@@ -1,6 +1,10 @@
 package login
 
-import "github.com/scorum/bitshares-go/caller"
+import (
+	"context"
+
+	"github.com/scorum/bitshares-go/caller"
+)
 
 const APIID = 1
 
@@ -12,36 +16,61 @@ func NewAPI(caller caller.Caller) *API {
 	return &API{caller}
 }
 
-func (api *API) call(method string, args []interface{}, reply interface{}) error {
-	return api.caller.Call(caller.APIID(APIID), method, args, reply)
+func (api *API) call(ctx context.Context, method string, args []interface{}, reply interface{}) error {
+	return caller.CallContext(ctx, api.caller, caller.APIID(APIID), method, args, reply)
 }
 
 func (api *API) GetApiByName(name string) (*uint8, error) {
+	return api.GetApiByNameContext(context.Background(), name)
+}
+
+// GetApiByNameContext is GetApiByName with a context
+func (api *API) GetApiByNameContext(ctx context.Context, name string) (*uint8, error) {
 	var id uint8
-	err := api.call("get_api_by_name", []interface{}{name}, &id)
+	err := api.call(ctx, "get_api_by_name", []interface{}{name}, &id)
 	return &id, err
 }
 
 func (api *API) Login(username, password string) (bool, error) {
+	return api.LoginContext(context.Background(), username, password)
+}
+
+// LoginContext is Login with a context
+func (api *API) LoginContext(ctx context.Context, username, password string) (bool, error) {
 	var resp bool
-	err := api.call("login", []interface{}{username, password}, &resp)
+	err := api.call(ctx, "login", []interface{}{username, password}, &resp)
 	return resp, err
 }
 
 func (api *API) Database() (caller.APIID, error) {
+	return api.DatabaseContext(context.Background())
+}
+
+// DatabaseContext is Database with a context
+func (api *API) DatabaseContext(ctx context.Context) (caller.APIID, error) {
 	var id caller.APIID
-	err := api.call("database", caller.EmptyParams, &id)
+	err := api.call(ctx, "database", caller.EmptyParams, &id)
 	return id, err
 }
 
 func (api *API) History() (caller.APIID, error) {
+	return api.HistoryContext(context.Background())
+}
+
+// HistoryContext is History with a context
+func (api *API) HistoryContext(ctx context.Context) (caller.APIID, error) {
 	var id caller.APIID
-	err := api.call("history", caller.EmptyParams, &id)
+	err := api.call(ctx, "history", caller.EmptyParams, &id)
 	return id, err
 }
 
 func (api *API) NetworkBroadcast() (caller.APIID, error) {
+	return api.NetworkBroadcastContext(context.Background())
+}
+
+// NetworkBroadcastContext is NetworkBroadcast with a context
+func (api *API) NetworkBroadcastContext(ctx context.Context) (caller.APIID, error) {
 	var id caller.APIID
-	err := api.call("network_broadcast", caller.EmptyParams, &id)
+	err := api.call(ctx, "network_broadcast", caller.EmptyParams, &id)
 	return id, err
 }
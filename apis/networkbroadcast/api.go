@@ -1,6 +1,7 @@
 package networkbroadcast
 
 import (
+	"context"
 	"github.com/scorum/bitshares-go/caller"
 	"github.com/scorum/bitshares-go/types"
 )
@@ -14,18 +15,28 @@ func NewAPI(id caller.APIID, caller caller.Caller) *API {
 	return &API{id: id, caller: caller}
 }
 
-func (api *API) call(method string, args []interface{}, reply interface{}) error {
-	return api.caller.Call(api.id, method, args, reply)
+func (api *API) call(ctx context.Context, method string, args []interface{}, reply interface{}) error {
+	return caller.CallContext(ctx, api.caller, api.id, method, args, reply)
 }
 
 // BroadcastTransaction broadcast a transaction to the network.
 func (api *API) BroadcastTransaction(tx *types.Transaction) error {
-	return api.call("broadcast_transaction", []interface{}{tx}, nil)
+	return api.BroadcastTransactionContext(context.Background(), tx)
+}
+
+// BroadcastTransactionContext is BroadcastTransaction with a context
+func (api *API) BroadcastTransactionContext(ctx context.Context, tx *types.Transaction) error {
+	return api.call(ctx, "broadcast_transaction", []interface{}{tx}, nil)
 }
 
 func (api *API) BroadcastTransactionSynchronous(tx *types.Transaction) (*BroadcastResponse, error) {
+	return api.BroadcastTransactionSynchronousContext(context.Background(), tx)
+}
+
+// BroadcastTransactionSynchronousContext is BroadcastTransactionSynchronous with a context
+func (api *API) BroadcastTransactionSynchronousContext(ctx context.Context, tx *types.Transaction) (*BroadcastResponse, error) {
 	response := BroadcastResponse{}
-	err := api.call("broadcast_transaction_synchronous", []interface{}{tx}, &response)
+	err := api.call(ctx, "broadcast_transaction_synchronous", []interface{}{tx}, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,70 @@
+package bitshares
+
+import (
+	"context"
+
+	"github.com/scorum/bitshares-go/caller"
+)
+
+// Batch collects the calls sent together by Client.Batch.
+// The replies are filled once the batch is sent, the errors are reported per call.
+type Batch struct {
+	requests []*caller.BatchRequest
+
+	// the calls of the APIs resolved on send
+	database []*caller.BatchRequest
+	history  []*caller.BatchRequest
+}
+
+// Call adds a call of the API with the given ID
+func (b *Batch) Call(api caller.APIID, method string, args []interface{}, reply interface{}) *caller.BatchRequest {
+	r := &caller.BatchRequest{API: api, Method: method, Args: args, Reply: reply}
+	b.requests = append(b.requests, r)
+	return r
+}
+
+// Database adds a database_api call, e.g.
+//
+//	var objects []types.Asset
+//	b.Database("get_objects", []interface{}{ids}, &objects)
+func (b *Batch) Database(method string, args []interface{}, reply interface{}) *caller.BatchRequest {
+	r := b.Call(0, method, args, reply)
+	b.database = append(b.database, r)
+	return r
+}
+
+// History adds a history_api call
+func (b *Batch) History(method string, args []interface{}, reply interface{}) *caller.BatchRequest {
+	r := b.Call(0, method, args, reply)
+	b.history = append(b.history, r)
+	return r
+}
+
+// Batch sends the calls added by fn at once, see BatchContext
+func (client *Client) Batch(fn func(b *Batch)) error {
+	return client.BatchContext(context.Background(), fn)
+}
+
+// BatchContext sends the calls added by fn at once and waits for all of the replies.
+// It returns the first error in the order of the calls, every call has its own error as well.
+// The websocket transport writes the whole batch in one go, the other transports make the calls concurrently.
+// The interceptors set with WithInterceptors see every call of the batch on its own.
+func (client *Client) BatchContext(ctx context.Context, fn func(b *Batch)) error {
+	b := &Batch{}
+	fn(b)
+
+	for _, r := range b.database {
+		r.API = client.databaseAPIID
+	}
+	if len(b.history) > 0 {
+		id, err := client.historyAPI.apiID(ctx)
+		if err != nil {
+			return err
+		}
+		for _, r := range b.history {
+			r.API = id
+		}
+	}
+
+	return caller.BatchCall(ctx, client.cc, b.requests)
+}
@@ -0,0 +1,61 @@
+package caller
+
+import (
+	"context"
+	"sync"
+)
+
+// batchConcurrency limits the number of calls in flight when the caller has no batch support
+const batchConcurrency = 32
+
+// BatchRequest is a single call of a batch.
+// Once the batch is sent either the Reply is filled or the Err is set.
+type BatchRequest struct {
+	API    APIID
+	Method string
+	Args   []interface{}
+	Reply  interface{}
+	Err    error
+}
+
+// BatchCaller is a Caller which sends several calls at once
+type BatchCaller interface {
+	Caller
+	BatchCall(ctx context.Context, requests []*BatchRequest) error
+}
+
+// BatchCall makes all of the calls and returns the first error in the order of the requests.
+// If the caller does not implement BatchCaller the calls are made concurrently.
+func BatchCall(ctx context.Context, c Caller, requests []*BatchRequest) error {
+	if bc, ok := c.(BatchCaller); ok {
+		return bc.BatchCall(ctx, requests)
+	}
+	return callConcurrently(ctx, c, requests)
+}
+
+// callConcurrently makes the calls of the batch one by one, up to batchConcurrency at once
+func callConcurrently(ctx context.Context, c Caller, requests []*BatchRequest) error {
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, batchConcurrency)
+	for _, r := range requests {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(r *BatchRequest) {
+			defer wg.Done()
+			r.Err = CallContext(ctx, c, r.API, r.Method, r.Args, r.Reply)
+			<-semaphore
+		}(r)
+	}
+	wg.Wait()
+	return firstError(requests)
+}
+
+// firstError returns the first error in the order of the requests
+func firstError(requests []*BatchRequest) error {
+	for _, r := range requests {
+		if r.Err != nil {
+			return r.Err
+		}
+	}
+	return nil
+}
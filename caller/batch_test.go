@@ -0,0 +1,28 @@
+package caller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchCall(t *testing.T) {
+	c := &slowCaller{delay: 100 * time.Millisecond}
+
+	replies := make([]string, 10)
+	requests := make([]*BatchRequest, len(replies))
+	for i := range requests {
+		requests[i] = &BatchRequest{API: 2, Method: "method", Args: EmptyParams, Reply: &replies[i]}
+	}
+
+	start := time.Now()
+	require.NoError(t, BatchCall(context.Background(), c, requests))
+	require.True(t, time.Since(start) < time.Second, "the calls are not concurrent")
+
+	for i := range replies {
+		require.Equal(t, "method", replies[i])
+		require.NoError(t, requests[i].Err)
+	}
+}
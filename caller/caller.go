@@ -1,6 +1,7 @@
 package caller
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 )
@@ -14,7 +15,54 @@ type Caller interface {
 	SetCallback(api APIID, method string, callback func(raw json.RawMessage)) error
 }
 
+// ContextCaller is a Caller which can cancel the call once the context is done
+type ContextCaller interface {
+	Caller
+	CallContext(ctx context.Context, api APIID, method string, args []interface{}, reply interface{}) error
+}
+
+// CallbackResetter is a Caller which can drop all of its callbacks locally, without a call to the node,
+// e.g. when the node is unreachable and the callbacks are registered on another node
+type CallbackResetter interface {
+	Caller
+	ResetCallbacks()
+}
+
 type CallCloser interface {
 	Caller
 	io.Closer
 }
+
+// CallContext invokes the method using the given caller.
+// If the caller does not implement ContextCaller the call is made in a separate goroutine
+// and CallContext returns ctx.Err() as soon as the context is done without waiting for the call.
+func CallContext(ctx context.Context, c Caller, api APIID, method string, args []interface{}, reply interface{}) error {
+	if cc, ok := c.(ContextCaller); ok {
+		return cc.CallContext(ctx, api, method, args, reply)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// the reply is unmarshalled only if the call completes in time,
+	// so the abandoned call never touches it
+	var raw json.RawMessage
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Call(api, method, args, &raw)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+		if reply != nil && raw != nil {
+			return json.Unmarshal(raw, reply)
+		}
+		return nil
+	}
+}
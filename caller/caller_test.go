@@ -0,0 +1,55 @@
+package caller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// slowCaller is a plain Caller without context support
+type slowCaller struct {
+	delay time.Duration
+}
+
+func (c *slowCaller) Call(api APIID, method string, args []interface{}, reply interface{}) error {
+	time.Sleep(c.delay)
+	return json.Unmarshal([]byte(`"`+method+`"`), reply)
+}
+
+func (c *slowCaller) SetCallback(api APIID, method string, callback func(raw json.RawMessage)) error {
+	return nil
+}
+
+func TestCallContext(t *testing.T) {
+	c := &slowCaller{delay: 100 * time.Millisecond}
+
+	t.Run("completed", func(t *testing.T) {
+		var reply string
+		require.NoError(t, CallContext(context.Background(), c, 1, "method", EmptyParams, &reply))
+		require.Equal(t, "method", reply)
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		var reply string
+		err := CallContext(ctx, c, 1, "method", EmptyParams, &reply)
+		require.Equal(t, context.DeadlineExceeded, err)
+
+		// the abandoned call does not write the reply
+		time.Sleep(200 * time.Millisecond)
+		require.Empty(t, reply)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := CallContext(ctx, c, 1, "method", EmptyParams, nil)
+		require.Equal(t, context.Canceled, err)
+	})
+}
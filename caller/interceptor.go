@@ -0,0 +1,187 @@
+package caller
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Invoker makes the call, it is either the next interceptor in the chain or the underlying caller
+type Invoker func(ctx context.Context, api APIID, method string, args []interface{}, reply interface{}) error
+
+// Interceptor wraps every call made through the caller returned by Intercept.
+// It has to call invoke to proceed with the call, it may also inspect or replace the args,
+// retry the call or return an error without making it.
+// The reply is filled in once invoke has returned without an error.
+type Interceptor func(ctx context.Context, api APIID, method string, args []interface{}, reply interface{}, invoke Invoker) error
+
+// Call describes a completed call, it is passed to the Observe callback
+type Call struct {
+	API      APIID
+	Method   string
+	Args     []interface{}
+	Reply    interface{}
+	Duration time.Duration
+	Err      error
+}
+
+// Observe creates an Interceptor passing every completed call to fn, e.g. for logging or metrics
+func Observe(fn func(call Call)) Interceptor {
+	return func(ctx context.Context, api APIID, method string, args []interface{}, reply interface{}, invoke Invoker) error {
+		start := time.Now()
+		err := invoke(ctx, api, method, args, reply)
+		fn(Call{
+			API:      api,
+			Method:   method,
+			Args:     args,
+			Reply:    reply,
+			Duration: time.Since(start),
+			Err:      err,
+		})
+		return err
+	}
+}
+
+// Intercept wraps the caller with the given interceptors.
+// The first interceptor is the outermost one, i.e. it is called first and sees the final result.
+// Callbacks are registered on the underlying caller as is, notices are not intercepted.
+// A batch is sent in one go if the caller implements BatchCaller, see interceptedCaller.BatchCall.
+func Intercept(c CallCloser, interceptors ...Interceptor) CallCloser {
+	if len(interceptors) == 0 {
+		return c
+	}
+
+	ic := &interceptedCaller{next: c, interceptors: interceptors}
+	ic.invoke = ic.chain(func(ctx context.Context, api APIID, method string, args []interface{}, reply interface{}) error {
+		return CallContext(ctx, c, api, method, args, reply)
+	})
+	return ic
+}
+
+func chain(interceptor Interceptor, next Invoker) Invoker {
+	return func(ctx context.Context, api APIID, method string, args []interface{}, reply interface{}) error {
+		return interceptor(ctx, api, method, args, reply, next)
+	}
+}
+
+type interceptedCaller struct {
+	next         CallCloser
+	interceptors []Interceptor
+	invoke       Invoker
+}
+
+// chain wraps the invoker with all of the interceptors
+func (c *interceptedCaller) chain(invoke Invoker) Invoker {
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		invoke = chain(c.interceptors[i], invoke)
+	}
+	return invoke
+}
+
+func (c *interceptedCaller) Call(api APIID, method string, args []interface{}, reply interface{}) error {
+	return c.invoke(context.Background(), api, method, args, reply)
+}
+
+func (c *interceptedCaller) CallContext(ctx context.Context, api APIID, method string, args []interface{}, reply interface{}) error {
+	return c.invoke(ctx, api, method, args, reply)
+}
+
+// BatchCall implements BatchCaller. Every request goes through the interceptors on its own,
+// the requests which make it to the underlying caller are sent in a single batch if it implements BatchCaller.
+// A request invoked once the batch is sent, e.g. retried by an interceptor, is sent as a single call.
+func (c *interceptedCaller) BatchCall(ctx context.Context, requests []*BatchRequest) error {
+	bc, ok := c.next.(BatchCaller)
+	if !ok {
+		return callConcurrently(ctx, c, requests)
+	}
+
+	b := &interceptedBatch{
+		waiting: len(requests),
+		settled: make([]bool, len(requests)),
+		ready:   make(chan struct{}),
+		sent:    make(chan struct{}),
+	}
+	if len(requests) == 0 {
+		close(b.ready)
+	}
+
+	var wg sync.WaitGroup
+	for i, r := range requests {
+		wg.Add(1)
+		go func(i int, r *BatchRequest) {
+			defer wg.Done()
+			invoke := c.chain(func(ctx context.Context, api APIID, method string, args []interface{}, reply interface{}) error {
+				queued := &BatchRequest{API: api, Method: method, Args: args, Reply: reply}
+				if !b.queue(i, queued) {
+					return CallContext(ctx, c.next, api, method, args, reply)
+				}
+				<-b.sent
+				return queued.Err
+			})
+			r.Err = invoke(ctx, r.API, r.Method, r.Args, r.Reply)
+			b.settle(i)
+		}(i, r)
+	}
+
+	// every request has either reached the underlying caller or returned
+	<-b.ready
+	b.mutex.Lock()
+	queued := b.queued
+	b.mutex.Unlock()
+	if len(queued) > 0 {
+		bc.BatchCall(ctx, queued)
+	}
+	close(b.sent)
+
+	wg.Wait()
+	return firstError(requests)
+}
+
+// interceptedBatch collects the requests of a batch which have passed the interceptors
+type interceptedBatch struct {
+	mutex   sync.Mutex
+	waiting int    // requests which have neither been queued nor returned
+	settled []bool // the i-th request has been queued or has returned
+	queued  []*BatchRequest
+	ready   chan struct{} // closed once nothing is waiting
+	sent    chan struct{} // closed once the batch is sent
+}
+
+// queue adds the i-th request to the batch unless it has been settled already,
+// e.g. an interceptor invokes it once again
+func (b *interceptedBatch) queue(i int, r *BatchRequest) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.settled[i] {
+		return false
+	}
+	b.queued = append(b.queued, r)
+	b.settleLocked(i)
+	return true
+}
+
+// settle reports the i-th request has returned
+func (b *interceptedBatch) settle(i int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if !b.settled[i] {
+		b.settleLocked(i)
+	}
+}
+
+func (b *interceptedBatch) settleLocked(i int) {
+	b.settled[i] = true
+	b.waiting--
+	if b.waiting == 0 {
+		close(b.ready)
+	}
+}
+
+func (c *interceptedCaller) SetCallback(api APIID, method string, callback func(raw json.RawMessage)) error {
+	return c.next.SetCallback(api, method, callback)
+}
+
+func (c *interceptedCaller) Close() error {
+	return c.next.Close()
+}
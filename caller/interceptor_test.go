@@ -0,0 +1,152 @@
+package caller
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type closer struct {
+	slowCaller
+	closed bool
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestIntercept(t *testing.T) {
+	var order []string
+	trace := func(name string) Interceptor {
+		return func(ctx context.Context, api APIID, method string, args []interface{}, reply interface{}, invoke Invoker) error {
+			order = append(order, name+" before")
+			err := invoke(ctx, api, method, args, reply)
+			order = append(order, name+" after")
+			return err
+		}
+	}
+
+	var calls []Call
+	observe := Observe(func(call Call) {
+		calls = append(calls, call)
+	})
+
+	next := &closer{}
+	c := Intercept(next, trace("outer"), trace("inner"), observe)
+
+	var reply string
+	require.NoError(t, c.Call(2, "get_chain_id", EmptyParams, &reply))
+	require.Equal(t, "get_chain_id", reply)
+	require.Equal(t, []string{"outer before", "inner before", "inner after", "outer after"}, order)
+
+	require.Len(t, calls, 1)
+	require.Equal(t, APIID(2), calls[0].API)
+	require.Equal(t, "get_chain_id", calls[0].Method)
+	require.Equal(t, &reply, calls[0].Reply)
+	require.NoError(t, calls[0].Err)
+
+	require.NoError(t, c.Close())
+	require.True(t, next.closed)
+}
+
+func TestIntercept_ShortCircuit(t *testing.T) {
+	errDenied := errors.New("denied")
+	deny := func(ctx context.Context, api APIID, method string, args []interface{}, reply interface{}, invoke Invoker) error {
+		return errDenied
+	}
+
+	var observed error
+	c := Intercept(&closer{}, Observe(func(call Call) { observed = call.Err }), deny)
+
+	err := CallContext(context.Background(), c, 2, "get_chain_id", EmptyParams, nil)
+	require.Equal(t, errDenied, err)
+	require.Equal(t, errDenied, observed)
+}
+
+// batchCaller records the methods of every batch, the calls of "flaky" fail in a batch
+type batchCaller struct {
+	closer
+	mutex   sync.Mutex
+	batches [][]string
+}
+
+var errFlaky = errors.New("flaky")
+
+func (c *batchCaller) BatchCall(ctx context.Context, requests []*BatchRequest) error {
+	var methods []string
+	for _, r := range requests {
+		methods = append(methods, r.Method)
+		if r.Method == "flaky" {
+			r.Err = errFlaky
+			continue
+		}
+		r.Err = json.Unmarshal([]byte(`"`+r.Method+`"`), r.Reply)
+	}
+	sort.Strings(methods)
+
+	c.mutex.Lock()
+	c.batches = append(c.batches, methods)
+	c.mutex.Unlock()
+	return firstError(requests)
+}
+
+func TestIntercept_BatchCall(t *testing.T) {
+	errDenied := errors.New("denied")
+	deny := func(ctx context.Context, api APIID, method string, args []interface{}, reply interface{}, invoke Invoker) error {
+		if method == "denied" {
+			return errDenied
+		}
+		return invoke(ctx, api, method, args, reply)
+	}
+	retry := func(ctx context.Context, api APIID, method string, args []interface{}, reply interface{}, invoke Invoker) error {
+		if err := invoke(ctx, api, method, args, reply); err != errFlaky {
+			return err
+		}
+		return invoke(ctx, api, method, args, reply)
+	}
+
+	var mutex sync.Mutex
+	var observed []string
+	observe := Observe(func(call Call) {
+		mutex.Lock()
+		observed = append(observed, call.Method)
+		mutex.Unlock()
+	})
+
+	next := &batchCaller{}
+	c := Intercept(next, observe, deny, retry)
+
+	replies := make([]string, 4)
+	requests := make([]*BatchRequest, len(replies))
+	for i, method := range []string{"first", "flaky", "second", "denied"} {
+		requests[i] = &BatchRequest{API: 2, Method: method, Args: EmptyParams, Reply: &replies[i]}
+	}
+	require.Equal(t, errDenied, BatchCall(context.Background(), c, requests))
+
+	// the requests which passed the interceptors are sent in a single batch,
+	// the retried one is sent on its own afterwards
+	require.Equal(t, [][]string{{"first", "flaky", "second"}}, next.batches)
+	require.Equal(t, []string{"first", "flaky", "second", ""}, replies)
+	require.NoError(t, requests[0].Err)
+	require.NoError(t, requests[1].Err)
+	require.NoError(t, requests[2].Err)
+	require.Equal(t, errDenied, requests[3].Err)
+
+	sort.Strings(observed)
+	require.Equal(t, []string{"denied", "first", "flaky", "second"}, observed)
+
+	// a caller without batches
+	replies = make([]string, 2)
+	requests = []*BatchRequest{
+		{API: 2, Method: "first", Args: EmptyParams, Reply: &replies[0]},
+		{API: 2, Method: "second", Args: EmptyParams, Reply: &replies[1]},
+	}
+	require.NoError(t, BatchCall(context.Background(), Intercept(&closer{}, retry), requests))
+	require.Equal(t, []string{"first", "second"}, replies)
+}
@@ -0,0 +1,179 @@
+// Package ratelimit limits the rate of the calls with token buckets, e.g.
+//
+//	limited, err := ratelimit.Wrap(transport,
+//		ratelimit.WithGlobal(20, 5),
+//		ratelimit.WithMethod("get_block", 5, 1),
+//	)
+//
+// A call waits for a token of its method, if the method is limited, and then for a global token.
+// The wait is interrupted once the context of the call is done.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/caller"
+)
+
+// ErrInvalidRate is returned for a rate which is not positive
+var ErrInvalidRate = errors.New("rate must be positive")
+
+// Limiter is a token bucket refilled with rate tokens per second up to burst tokens
+type Limiter struct {
+	rate  float64
+	burst float64
+
+	mutex  sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter creates a full Limiter, ErrInvalidRate is returned if the rate is not positive
+func NewLimiter(rate float64, burst int) (*Limiter, error) {
+	if !(rate > 0) {
+		return nil, errors.Wrapf(ErrInvalidRate, "rate %v", rate)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}, nil
+}
+
+// Wait takes a token, waiting until one is available or the context is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token in advance and returns the time to wait for it
+func (l *Limiter) reserve() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns the reserved token
+func (l *Limiter) cancel() {
+	l.mutex.Lock()
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.mutex.Unlock()
+}
+
+type limits struct {
+	global  *Limiter
+	methods map[string]*Limiter
+	err     error // the first invalid option
+}
+
+// Option configures the limits
+type Option func(*limits)
+
+// WithGlobal limits all of the calls to rate per second with the given burst
+func WithGlobal(rate float64, burst int) Option {
+	return func(l *limits) {
+		limiter, err := NewLimiter(rate, burst)
+		if err != nil {
+			l.fail(errors.Wrap(err, "global limit"))
+			return
+		}
+		l.global = limiter
+	}
+}
+
+// WithMethod limits the calls of the method to rate per second with the given burst.
+// The calls are limited by the global limit as well.
+func WithMethod(method string, rate float64, burst int) Option {
+	return func(l *limits) {
+		limiter, err := NewLimiter(rate, burst)
+		if err != nil {
+			l.fail(errors.Wrapf(err, "limit of %s", method))
+			return
+		}
+		l.methods[method] = limiter
+	}
+}
+
+func (l *limits) fail(err error) {
+	if l.err == nil {
+		l.err = err
+	}
+}
+
+// New creates an interceptor limiting the rate of the calls, an error is returned for an invalid option
+func New(options ...Option) (caller.Interceptor, error) {
+	l := &limits{methods: make(map[string]*Limiter)}
+	for _, option := range options {
+		option(l)
+	}
+	if l.err != nil {
+		return nil, l.err
+	}
+
+	return func(ctx context.Context, api caller.APIID, method string, args []interface{}, reply interface{}, invoke caller.Invoker) error {
+		limiter, limited := l.methods[method]
+		if limited {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+		if l.global != nil {
+			if err := l.global.Wait(ctx); err != nil {
+				// the call is not made, so the token of the method is not spent
+				if limited {
+					limiter.cancel()
+				}
+				return err
+			}
+		}
+		return invoke(ctx, api, method, args, reply)
+	}, nil
+}
+
+// Wrap limits the rate of the calls made through the caller, an error is returned for an invalid option
+func Wrap(c caller.CallCloser, options ...Option) (caller.CallCloser, error) {
+	interceptor, err := New(options...)
+	if err != nil {
+		return nil, err
+	}
+	return caller.Intercept(c, interceptor), nil
+}
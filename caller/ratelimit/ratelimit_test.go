@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/stretchr/testify/require"
+)
+
+type countingCaller struct {
+	calls map[string]int
+}
+
+func (c *countingCaller) Call(api caller.APIID, method string, args []interface{}, reply interface{}) error {
+	c.calls[method]++
+	return nil
+}
+
+func (c *countingCaller) SetCallback(api caller.APIID, method string, callback func(raw json.RawMessage)) error {
+	return nil
+}
+
+func (c *countingCaller) Close() error { return nil }
+
+func TestLimiter(t *testing.T) {
+	l, err := NewLimiter(20, 2)
+	require.NoError(t, err)
+
+	// the burst is available at once
+	start := time.Now()
+	require.NoError(t, l.Wait(context.Background()))
+	require.NoError(t, l.Wait(context.Background()))
+	require.True(t, time.Since(start) < 25*time.Millisecond)
+
+	// then the tokens come every 50ms
+	require.NoError(t, l.Wait(context.Background()))
+	require.NoError(t, l.Wait(context.Background()))
+	require.True(t, time.Since(start) >= 90*time.Millisecond)
+
+	t.Run("canceled", func(t *testing.T) {
+		l, err := NewLimiter(0.1, 1)
+		require.NoError(t, err)
+		require.NoError(t, l.Wait(context.Background()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.Equal(t, context.DeadlineExceeded, l.Wait(ctx))
+	})
+}
+
+func TestWrap(t *testing.T) {
+	next := &countingCaller{calls: make(map[string]int)}
+	c, err := Wrap(next, WithMethod("get_block", 1, 1))
+	require.NoError(t, err)
+
+	// the limit of get_block does not affect other methods
+	for i := 0; i < 10; i++ {
+		require.NoError(t, c.Call(2, "get_objects", caller.EmptyParams, nil))
+	}
+	require.NoError(t, c.Call(2, "get_block", caller.EmptyParams, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, caller.CallContext(ctx, c, 2, "get_block", caller.EmptyParams, nil))
+
+	require.Equal(t, map[string]int{"get_objects": 10, "get_block": 1}, next.calls)
+}
+
+func TestWrap_GlobalCanceled(t *testing.T) {
+	next := &countingCaller{calls: make(map[string]int)}
+	c, err := Wrap(next, WithGlobal(20, 1), WithMethod("get_block", 0.1, 2))
+	require.NoError(t, err)
+	require.NoError(t, c.Call(2, "get_block", caller.EmptyParams, nil))
+
+	// the global wait is canceled, the token of get_block is returned
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, caller.CallContext(ctx, c, 2, "get_block", caller.EmptyParams, nil))
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, caller.CallContext(ctx, c, 2, "get_block", caller.EmptyParams, nil))
+	require.Equal(t, map[string]int{"get_block": 2}, next.calls)
+}
+
+func TestNewLimiter_Rate(t *testing.T) {
+	_, err := NewLimiter(0, 1)
+	require.Equal(t, ErrInvalidRate, errors.Cause(err))
+
+	_, err = New(WithGlobal(-1, 1))
+	require.Equal(t, ErrInvalidRate, errors.Cause(err))
+
+	_, err = Wrap(&countingCaller{}, WithGlobal(1, 1), WithMethod("get_block", math.NaN(), 1))
+	require.Equal(t, ErrInvalidRate, errors.Cause(err))
+}
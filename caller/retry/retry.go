@@ -0,0 +1,251 @@
+// Package retry retries the calls failed with transient errors, e.g. a connection reset.
+//
+// The read-only methods are retried with an exponential backoff. A broadcast is never resubmitted
+// blindly: the transaction might have made it to the chain even though the response was lost,
+// so before every resubmission the node is asked whether the transaction ID is already known.
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/transport"
+)
+
+// ErrIncluded is returned by a synchronous broadcast if the response has been lost
+// but the transaction is found on the chain, so the result of the broadcast is unknown
+var ErrIncluded = errors.New("transaction is included in a block, the broadcast result is lost")
+
+// broadcasts are the methods submitting a transaction
+var broadcasts = map[string]bool{
+	"broadcast_transaction":               true,
+	"broadcast_transaction_synchronous":   true,
+	"broadcast_transaction_with_callback": true,
+}
+
+// unsafe are the methods which are never retried
+var unsafe = map[string]bool{
+	"broadcast_block": true,
+}
+
+type retrier struct {
+	maxAttempts int
+	min         time.Duration
+	max         time.Duration
+	factor      float64
+	retryable   func(err error) bool
+
+	mutex      sync.Mutex
+	databaseID caller.APIID
+}
+
+// Option configures the retries
+type Option func(*retrier)
+
+// WithMaxAttempts sets the number of attempts including the first one, 3 by default
+func WithMaxAttempts(attempts int) Option {
+	return func(r *retrier) {
+		r.maxAttempts = attempts
+	}
+}
+
+// WithBackoff sets the delays between the attempts: the first delay is min,
+// every next one is factor times longer up to max. 100ms, 2s and 2 by default.
+func WithBackoff(min, max time.Duration, factor float64) Option {
+	return func(r *retrier) {
+		r.min = min
+		r.max = max
+		r.factor = factor
+	}
+}
+
+// WithRetryable sets the function telling the transient errors, Retryable by default
+func WithRetryable(retryable func(err error) bool) Option {
+	return func(r *retrier) {
+		r.retryable = retryable
+	}
+}
+
+// Idempotent reports whether the method is safe to repeat after a failure: it does not submit anything to the chain
+func Idempotent(method string) bool {
+	return !broadcasts[method] && !unsafe[method]
+}
+
+// Retryable reports whether the error is transient: a lost connection, a network or an I/O error.
+// The errors returned by the node, the context errors, wrapped or not, and the errors of encoding
+// or decoding the messages are not, another attempt would fail the same way.
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, transport.ErrConnectionLost) {
+		return true
+	}
+
+	switch cause := errors.Cause(err); cause {
+	case io.EOF, io.ErrUnexpectedEOF, io.ErrClosedPipe:
+		return true
+	default:
+		var netErr net.Error
+		return errors.As(cause, &netErr)
+	}
+}
+
+// New creates an interceptor retrying the failed calls
+func New(options ...Option) caller.Interceptor {
+	r := &retrier{
+		maxAttempts: 3,
+		min:         100 * time.Millisecond,
+		max:         2 * time.Second,
+		factor:      2,
+		retryable:   Retryable,
+	}
+	for _, option := range options {
+		option(r)
+	}
+
+	return func(ctx context.Context, api caller.APIID, method string, args []interface{}, reply interface{}, invoke caller.Invoker) error {
+		switch {
+		case unsafe[method]:
+			return invoke(ctx, api, method, args, reply)
+		case broadcasts[method]:
+			return r.broadcast(ctx, api, method, args, reply, invoke)
+		default:
+			return r.call(ctx, api, method, args, reply, invoke)
+		}
+	}
+}
+
+// Wrap retries the calls made through the caller
+func Wrap(c caller.CallCloser, options ...Option) caller.CallCloser {
+	return caller.Intercept(c, New(options...))
+}
+
+// delay returns the delay before the given attempt, the first retry is attempt 1
+func (r *retrier) delay(attempt int) time.Duration {
+	d := float64(r.min)
+	for i := 1; i < attempt; i++ {
+		d *= r.factor
+	}
+	if d > float64(r.max) {
+		return r.max
+	}
+	return time.Duration(d)
+}
+
+// wait sleeps before the attempt unless the context is done
+func (r *retrier) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(r.delay(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *retrier) call(ctx context.Context, api caller.APIID, method string, args []interface{}, reply interface{}, invoke caller.Invoker) error {
+	err := invoke(ctx, api, method, args, reply)
+	for attempt := 1; attempt < r.maxAttempts && r.retryable(err); attempt++ {
+		if werr := r.wait(ctx, attempt); werr != nil {
+			return err
+		}
+		err = invoke(ctx, api, method, args, reply)
+	}
+	return err
+}
+
+// identified is a transaction with an ID, e.g. *types.Transaction
+type identified interface {
+	ID() (string, error)
+}
+
+func (r *retrier) broadcast(ctx context.Context, api caller.APIID, method string, args []interface{}, reply interface{}, invoke caller.Invoker) error {
+	err := invoke(ctx, api, method, args, reply)
+	if err == nil || !r.retryable(err) {
+		return err
+	}
+
+	// the transaction is the last argument, broadcast_transaction_with_callback takes the callback ID first
+	var id string
+	for _, arg := range args {
+		if tx, ok := arg.(identified); ok {
+			id, _ = tx.ID()
+		}
+	}
+	if id == "" {
+		return err
+	}
+
+	for attempt := 1; attempt < r.maxAttempts; attempt++ {
+		if werr := r.wait(ctx, attempt); werr != nil {
+			return err
+		}
+
+		// the transaction might have made it, resubmit it only if it is not known yet
+		included, checkErr := r.included(ctx, id, invoke)
+		if checkErr != nil {
+			if !r.retryable(checkErr) {
+				return err
+			}
+			continue
+		}
+		if included {
+			return r.done(reply)
+		}
+
+		err = invoke(ctx, api, method, args, reply)
+		if errors.Is(err, transport.ErrDuplicateTransaction) {
+			return r.done(reply)
+		}
+		if err == nil || !r.retryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// done is the result of a broadcast of a transaction found on the chain
+func (r *retrier) done(reply interface{}) error {
+	if reply != nil {
+		return ErrIncluded
+	}
+	return nil
+}
+
+// included asks the node whether the transaction is already known
+func (r *retrier) included(ctx context.Context, id string, invoke caller.Invoker) (bool, error) {
+	databaseID, err := r.database(ctx, invoke)
+	if err != nil {
+		return false, err
+	}
+
+	var trx json.RawMessage
+	if err := invoke(ctx, databaseID, "get_recent_transaction_by_id", []interface{}{id}, &trx); err != nil {
+		return false, err
+	}
+	return len(trx) > 0 && string(trx) != "null", nil
+}
+
+// database resolves the database API ID once
+func (r *retrier) database(ctx context.Context, invoke caller.Invoker) (caller.APIID, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.databaseID == 0 {
+		var id caller.APIID
+		if err := invoke(ctx, transport.LoginAPIID, "database", caller.EmptyParams, &id); err != nil {
+			return 0, err
+		}
+		r.databaseID = id
+	}
+	return r.databaseID, nil
+}
@@ -0,0 +1,167 @@
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/transport"
+	"github.com/scorum/bitshares-go/transport/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+var errConnection = &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+
+type tx string
+
+func (t tx) ID() (string, error) { return string(t), nil }
+
+// flakyNode fails the first calls of every method
+type flakyNode struct {
+	mutex    sync.Mutex
+	failures map[string]int
+	calls    map[string]int
+	known    map[string]bool // transactions on the chain
+	lost     bool            // the transaction is applied, but the response is lost
+}
+
+func newFlakyNode(failures map[string]int) *flakyNode {
+	return &flakyNode{failures: failures, calls: make(map[string]int), known: make(map[string]bool)}
+}
+
+func (n *flakyNode) Call(api caller.APIID, method string, args []interface{}, reply interface{}) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.calls[method]++
+	if n.failures[method] > 0 {
+		n.failures[method]--
+		if method == "broadcast_transaction" && n.lost {
+			n.known[string(args[0].(tx))] = true
+		}
+		return errConnection
+	}
+
+	var result interface{}
+	switch method {
+	case "database":
+		result = 2
+	case "get_recent_transaction_by_id":
+		if n.known[args[0].(string)] {
+			result = map[string]interface{}{"ref_block_num": 1}
+		}
+	case "broadcast_transaction":
+		id := string(args[0].(tx))
+		if n.known[id] {
+			return &transport.RPCError{Code: 1, Message: "duplicate transaction"}
+		}
+		n.known[id] = true
+	case "get_objects":
+		result = []string{"object"}
+	default:
+		return &transport.RPCError{Code: 1, Message: "unknown method " + method}
+	}
+
+	raw, _ := json.Marshal(result)
+	if reply != nil {
+		return json.Unmarshal(raw, reply)
+	}
+	return nil
+}
+
+func (n *flakyNode) SetCallback(api caller.APIID, method string, callback func(raw json.RawMessage)) error {
+	return nil
+}
+
+func (n *flakyNode) Close() error { return nil }
+
+func fast() Option {
+	return WithBackoff(time.Millisecond, time.Millisecond, 1)
+}
+
+func TestRetry_ReadOnly(t *testing.T) {
+	node := newFlakyNode(map[string]int{"get_objects": 2})
+	c := Wrap(node, fast())
+
+	var objects []string
+	require.NoError(t, c.Call(2, "get_objects", caller.EmptyParams, &objects))
+	require.Equal(t, []string{"object"}, objects)
+	require.Equal(t, 3, node.calls["get_objects"])
+
+	// the errors returned by the node are not retried
+	require.Error(t, c.Call(2, "get_unknown", caller.EmptyParams, nil))
+	require.Equal(t, 1, node.calls["get_unknown"])
+
+	// the attempts are limited
+	node.failures["get_objects"] = 5
+	require.Equal(t, errConnection, c.Call(2, "get_objects", caller.EmptyParams, &objects))
+	require.Equal(t, 6, node.calls["get_objects"])
+}
+
+func TestRetry_Broadcast(t *testing.T) {
+	t.Run("resubmitted", func(t *testing.T) {
+		node := newFlakyNode(map[string]int{"broadcast_transaction": 1})
+		c := Wrap(node, fast())
+
+		require.NoError(t, c.Call(4, "broadcast_transaction", []interface{}{tx("a")}, nil))
+		require.Equal(t, 2, node.calls["broadcast_transaction"])
+		require.Equal(t, 1, node.calls["get_recent_transaction_by_id"])
+	})
+
+	t.Run("response lost", func(t *testing.T) {
+		node := newFlakyNode(map[string]int{"broadcast_transaction": 1})
+		node.lost = true
+		c := Wrap(node, fast())
+
+		require.NoError(t, c.Call(4, "broadcast_transaction", []interface{}{tx("a")}, nil))
+		require.Equal(t, 1, node.calls["broadcast_transaction"])
+
+		// the result of a synchronous broadcast can not be restored
+		node.failures["broadcast_transaction"] = 1
+		var reply json.RawMessage
+		err := caller.CallContext(context.Background(), c, 4, "broadcast_transaction", []interface{}{tx("b")}, &reply)
+		require.Equal(t, ErrIncluded, err)
+	})
+
+	t.Run("check failed", func(t *testing.T) {
+		node := newFlakyNode(map[string]int{"broadcast_transaction": 1, "get_recent_transaction_by_id": 5})
+		c := Wrap(node, fast())
+
+		// the transaction is never resubmitted unless the node has confirmed it is unknown
+		require.Equal(t, errConnection, c.Call(4, "broadcast_transaction", []interface{}{tx("a")}, nil))
+		require.Equal(t, 1, node.calls["broadcast_transaction"])
+	})
+}
+
+func TestRetryable(t *testing.T) {
+	var syntaxErr error = &json.SyntaxError{}
+
+	for err, expected := range map[error]bool{
+		errConnection: true,
+		errors.Wrap(errConnection, "failed to call"):                   true,
+		io.ErrUnexpectedEOF:                                            true,
+		errors.Wrap(transport.ErrConnectionLost, "call"):               true,
+		&url.Error{Op: "Post", URL: "http://node", Err: errConnection}: true,
+		websocket.ErrReconnecting:                                      true,
+		websocket.ErrTimeout:                                           true,
+		websocket.ErrNoticeTimeout:                                     true,
+
+		&transport.RPCError{Code: 1}:                  false,
+		transport.ErrShutdown:                         false,
+		context.Canceled:                              false,
+		errors.Wrap(context.DeadlineExceeded, "call"): false,
+		&url.Error{Op: "Post", URL: "http://node", Err: context.DeadlineExceeded}: false,
+		syntaxErr: false,
+		errors.Wrap(syntaxErr, "failed to decode"): false,
+		&json.UnsupportedTypeError{}:               false,
+	} {
+		require.Equal(t, expected, Retryable(err), "%v", err)
+	}
+}
@@ -1,7 +1,7 @@
 package bitshares
 
 import (
-	"encoding/json"
+	"context"
 
 	"github.com/pkg/errors"
 	"github.com/scorum/bitshares-go/apis/database"
@@ -9,13 +9,18 @@ import (
 	"github.com/scorum/bitshares-go/apis/login"
 	"github.com/scorum/bitshares-go/apis/networkbroadcast"
 	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/logging"
 	"github.com/scorum/bitshares-go/sign"
+	"github.com/scorum/bitshares-go/transport/http"
 	"github.com/scorum/bitshares-go/transport/websocket"
 	"github.com/scorum/bitshares-go/types"
-	"log"
+	"strings"
 	"time"
 )
 
+// ErrChainIDMismatch is returned by NewClient if the node is not on the expected chain
+var ErrChainIDMismatch = errors.New("chain ID mismatch")
+
 type Client struct {
 	cc caller.CallCloser
 
@@ -32,51 +37,124 @@ type Client struct {
 	Login *login.API
 
 	chainID string
+	logger  logging.Logger
+
+	// API IDs used by the batches
+	databaseAPIID caller.APIID
+	historyAPI    *lazyAPI
+
+	// creation options
+	username        string
+	password        string
+	eager           map[string]bool
+	expectedChainID string
+	interceptors    []caller.Interceptor
 }
 
-// NewClient creates a new RPC client
-func NewClient(url string) (*Client, error) {
+// NewClient creates a new RPC client.
+// The transport is chosen by the URL scheme: http(s):// uses JSON-RPC over HTTP, ws(s):// uses websockets.
+// The URL is ignored if the CallCloser is given with WithCaller.
+func NewClient(url string, options ...Option) (*Client, error) {
+	client := &Client{
+		logger: logging.Nop,
+		eager:  map[string]bool{DatabaseAPI: true, HistoryAPI: true, NetworkBroadcastAPI: true},
+	}
+	for _, option := range options {
+		option(client)
+	}
+
 	// transport
-	transport, err := websocket.NewTransport(url)
-	if err != nil {
+	if client.cc == nil {
+		transport, err := dial(url, client.logger)
+		if err != nil {
+			return nil, err
+		}
+		client.cc = transport
+	}
+	client.cc = caller.Intercept(client.cc, client.interceptors...)
+
+	if err := client.init(); err != nil {
+		client.cc.Close()
 		return nil, err
 	}
+	return client, nil
+}
 
-	client := &Client{cc: transport}
+// NewClientWithCaller creates a new RPC client over the given CallCloser,
+// e.g. a failover pool or a recorded session
+func NewClientWithCaller(cc caller.CallCloser, options ...Option) (*Client, error) {
+	return NewClient("", append(options, WithCaller(cc))...)
+}
 
+// init logs in and resolves the APIs
+func (client *Client) init() error {
 	// login
-	loginAPI := login.NewAPI(transport)
+	loginAPI := login.NewAPI(client.cc)
 	client.Login = loginAPI
 
+	if client.username != "" {
+		ok, err := loginAPI.Login(client.username, client.password)
+		if err != nil {
+			return errors.Wrap(err, "failed to login")
+		}
+		if !ok {
+			return errors.Errorf("login as %s is rejected", client.username)
+		}
+	}
+
 	// database
 	databaseAPIID, err := loginAPI.Database()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	client.Database = database.NewAPI(databaseAPIID, client.cc)
+	client.databaseAPIID = databaseAPIID
 
 	// chain ID
 	chainID, err := client.Database.GetChainID()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get chain ID")
+		return errors.Wrap(err, "failed to get chain ID")
+	}
+	if client.expectedChainID != "" && *chainID != client.expectedChainID {
+		return errors.Wrapf(ErrChainIDMismatch, "node is on %s, expected %s", *chainID, client.expectedChainID)
 	}
 	client.chainID = *chainID
 
 	// history
-	historyAPIID, err := loginAPI.History()
+	historyCaller, err := client.api(HistoryAPI, loginAPI.HistoryContext)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	client.History = history.NewAPI(historyAPIID, client.cc)
+	client.History = history.NewAPI(0, historyCaller)
+	client.historyAPI = historyCaller
 
 	// network broadcast
-	networkBroadcastAPIID, err := loginAPI.NetworkBroadcast()
+	networkBroadcastCaller, err := client.api(NetworkBroadcastAPI, loginAPI.NetworkBroadcastContext)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	client.NetworkBroadcast = networkbroadcast.NewAPI(networkBroadcastAPIID, client.cc)
+	client.NetworkBroadcast = networkbroadcast.NewAPI(0, networkBroadcastCaller)
 
-	return client, nil
+	return nil
+}
+
+// api creates the caller of the API, the API ID is resolved either now or on the first use
+func (client *Client) api(name string, resolve func(ctx context.Context) (caller.APIID, error)) (*lazyAPI, error) {
+	api := newLazyAPI(name, client.cc, resolve)
+	if client.eager[name] {
+		if _, err := api.apiID(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	return api, nil
+}
+
+// dial creates the transport matching the URL scheme
+func dial(url string, logger logging.Logger) (caller.CallCloser, error) {
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		return http.NewTransport(url, http.WithLogger(logger))
+	}
+	return websocket.NewTransport(url, websocket.WithLogger(logger))
 }
 
 // Close should be used to close the client when no longer needed.
@@ -85,26 +163,56 @@ func (client *Client) Close() error {
 	return client.cc.Close()
 }
 
-// Transfer a certain amount of the given asset
+// Transfer a certain amount of the given asset, the transaction is signed with the WIF key
 func (client *Client) Transfer(key string, from, to types.ObjectID, amount, fee types.AssetAmount) error {
+	return client.TransferContext(context.Background(), key, from, to, amount, fee)
+}
+
+// TransferContext is Transfer with a context
+func (client *Client) TransferContext(ctx context.Context, key string, from, to types.ObjectID, amount, fee types.AssetAmount) error {
+	signer, err := wifSigner(key)
+	if err != nil {
+		return err
+	}
+	return client.TransferWith(ctx, signer, from, to, amount, fee)
+}
+
+// TransferWith is TransferContext signing with the signer,
+// e.g. a remote.Signer so the private key never gets to the client process
+func (client *Client) TransferWith(ctx context.Context, signer sign.Signer, from, to types.ObjectID, amount, fee types.AssetAmount) error {
 	op := types.NewTransferOperation(from, to, amount, fee)
 
-	fees, err := client.Database.GetRequiredFee([]types.Operation{op}, fee.AssetID.String())
+	fees, err := client.Database.GetRequiredFeeContext(ctx, []types.Operation{op}, fee.AssetID.String())
 	if err != nil {
-		log.Println(err)
+		client.logger.Debug("failed to get fees", "error", err)
 		return errors.Wrap(err, "can't get fees")
 	}
 	op.Fee.Amount = fees[0].Amount
 
-	stx, err := client.sign([]string{key}, op)
+	stx, err := client.sign(ctx, []sign.Signer{signer}, op)
 	if err != nil {
 		return err
 	}
-	return client.broadcast(stx)
+	return client.broadcast(ctx, stx)
 }
 
+// LimitOrderCreate creates a limit order and returns its ID, the transaction is signed with the WIF key
 func (client *Client) LimitOrderCreate(key string, seller types.ObjectID, fee, amToSell, minToRecive types.AssetAmount, expiration time.Duration, fillOrKill bool) (string, error) {
-	props, err := client.Database.GetDynamicGlobalProperties()
+	return client.LimitOrderCreateContext(context.Background(), key, seller, fee, amToSell, minToRecive, expiration, fillOrKill)
+}
+
+// LimitOrderCreateContext is LimitOrderCreate with a context
+func (client *Client) LimitOrderCreateContext(ctx context.Context, key string, seller types.ObjectID, fee, amToSell, minToRecive types.AssetAmount, expiration time.Duration, fillOrKill bool) (string, error) {
+	signer, err := wifSigner(key)
+	if err != nil {
+		return "", err
+	}
+	return client.LimitOrderCreateWith(ctx, signer, seller, fee, amToSell, minToRecive, expiration, fillOrKill)
+}
+
+// LimitOrderCreateWith is LimitOrderCreateContext signing with the signer
+func (client *Client) LimitOrderCreateWith(ctx context.Context, signer sign.Signer, seller types.ObjectID, fee, amToSell, minToRecive types.AssetAmount, expiration time.Duration, fillOrKill bool) (string, error) {
+	props, err := client.Database.GetDynamicGlobalPropertiesContext(ctx)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to get dynamic global properties")
 	}
@@ -116,21 +224,20 @@ func (client *Client) LimitOrderCreate(key string, seller types.ObjectID, fee, a
 		MinToReceive: minToRecive,
 		Expiration:   types.NewTime(props.Time.Add(expiration)),
 		FillOrKill:   fillOrKill,
-		Extensions:   []json.RawMessage{},
 	}
 
-	fees, err := client.Database.GetRequiredFee([]types.Operation{op}, fee.AssetID.String())
+	fees, err := client.Database.GetRequiredFeeContext(ctx, []types.Operation{op}, fee.AssetID.String())
 	if err != nil {
-		log.Println(err)
+		client.logger.Debug("failed to get fees", "error", err)
 		return "", errors.Wrap(err, "can't get fees")
 	}
 	op.Fee.Amount = fees[0].Amount
 
-	stx, err := client.sign([]string{key}, op)
+	stx, err := client.sign(ctx, []sign.Signer{signer}, op)
 	if err != nil {
 		return "", err
 	}
-	result, err := client.broadcastSync(stx)
+	result, err := client.broadcastSync(ctx, stx)
 	if err != nil {
 		return "", err
 	}
@@ -152,35 +259,49 @@ func (client *Client) LimitOrderCreate(key string, seller types.ObjectID, fee, a
 	return id, err
 }
 
+// LimitOrderCancel cancels the limit order, the transaction is signed with the WIF key
 func (client *Client) LimitOrderCancel(key string, feePayingAccount, order types.ObjectID, fee types.AssetAmount) error {
+	return client.LimitOrderCancelContext(context.Background(), key, feePayingAccount, order, fee)
+}
+
+// LimitOrderCancelContext is LimitOrderCancel with a context
+func (client *Client) LimitOrderCancelContext(ctx context.Context, key string, feePayingAccount, order types.ObjectID, fee types.AssetAmount) error {
+	signer, err := wifSigner(key)
+	if err != nil {
+		return err
+	}
+	return client.LimitOrderCancelWith(ctx, signer, feePayingAccount, order, fee)
+}
+
+// LimitOrderCancelWith is LimitOrderCancelContext signing with the signer
+func (client *Client) LimitOrderCancelWith(ctx context.Context, signer sign.Signer, feePayingAccount, order types.ObjectID, fee types.AssetAmount) error {
 	op := &types.LimitOrderCancelOperation{
 		Fee:              fee,
 		FeePayingAccount: feePayingAccount,
 		Order:            order,
-		Extensions:       []json.RawMessage{},
 	}
 
-	fees, err := client.Database.GetRequiredFee([]types.Operation{op}, fee.AssetID.String())
+	fees, err := client.Database.GetRequiredFeeContext(ctx, []types.Operation{op}, fee.AssetID.String())
 	if err != nil {
-		log.Println(err)
+		client.logger.Debug("failed to get fees", "error", err)
 		return errors.Wrap(err, "can't get fees")
 	}
 	op.Fee.Amount = fees[0].Amount
 
-	stx, err := client.sign([]string{key}, op)
+	stx, err := client.sign(ctx, []sign.Signer{signer}, op)
 	if err != nil {
 		return err
 	}
-	return client.broadcast(stx)
+	return client.broadcast(ctx, stx)
 }
 
-func (client *Client) sign(wifs []string, operations ...types.Operation) (*sign.SignedTransaction, error) {
-	props, err := client.Database.GetDynamicGlobalProperties()
+func (client *Client) sign(ctx context.Context, signers []sign.Signer, operations ...types.Operation) (*sign.SignedTransaction, error) {
+	props, err := client.Database.GetDynamicGlobalPropertiesContext(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get dynamic global properties")
 	}
 
-	block, err := client.Database.GetBlock(props.LastIrreversibleBlockNum)
+	block, err := client.Database.GetBlockContext(ctx, props.LastIrreversibleBlockNum)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get block")
 	}
@@ -201,17 +322,27 @@ func (client *Client) sign(wifs []string, operations ...types.Operation) (*sign.
 		stx.PushOperation(op)
 	}
 
-	if err = stx.Sign(wifs, client.chainID); err != nil {
+	if err = stx.SignWith(ctx, client.chainID, signers...); err != nil {
 		return nil, errors.Wrap(err, "failed to sign the transaction")
 	}
+	client.logger.Debug("transaction is signed", "operations", len(operations), "expiration", expiration)
 
 	return stx, nil
 }
 
-func (client *Client) broadcast(stx *sign.SignedTransaction) error {
-	return client.NetworkBroadcast.BroadcastTransaction(stx.Transaction)
+// wifSigner returns the signer of the WIF key
+func wifSigner(wif string) (sign.Signer, error) {
+	signer, err := sign.NewWIFSigner(wif)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign the transaction")
+	}
+	return signer, nil
+}
+
+func (client *Client) broadcast(ctx context.Context, stx *sign.SignedTransaction) error {
+	return client.NetworkBroadcast.BroadcastTransactionContext(ctx, stx.Transaction)
 }
 
-func (client *Client) broadcastSync(stx *sign.SignedTransaction) (*networkbroadcast.BroadcastResponse, error) {
-	return client.NetworkBroadcast.BroadcastTransactionSynchronous(stx.Transaction)
+func (client *Client) broadcastSync(ctx context.Context, stx *sign.SignedTransaction) (*networkbroadcast.BroadcastResponse, error) {
+	return client.NetworkBroadcast.BroadcastTransactionSynchronousContext(ctx, stx.Transaction)
 }
@@ -1,21 +1,29 @@
 package bitshares
 
 import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/apis/database"
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/nodetest"
+	"github.com/scorum/bitshares-go/sign"
+	"github.com/scorum/bitshares-go/transport/replay"
+	"github.com/scorum/bitshares-go/transport/websocket"
 	"github.com/scorum/bitshares-go/types"
 	"github.com/stretchr/testify/require"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 )
 
-const (
-	testNet = "wss://node.testnet.bitshares.eu"
-	mainNet = "wss://bitshares.openledger.info/ws"
-)
-
 func TestClient(t *testing.T) {
 	t.Run("valid ws url", func(t *testing.T) {
-		_, err := NewClient(mainNet)
+		_, err := NewClient(newServer(t).URL)
 		require.NoError(t, err)
 	})
 
@@ -25,8 +33,25 @@ func TestClient(t *testing.T) {
 	})
 }
 
+func TestClient_Interceptors(t *testing.T) {
+	var methods []string
+	observe := caller.Observe(func(call caller.Call) {
+		require.NoError(t, call.Err)
+		methods = append(methods, call.Method)
+	})
+
+	client, err := NewClient(newServer(t).URL, WithInterceptors(observe))
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Database.LookupAssetSymbols("TEST")
+	require.NoError(t, err)
+	require.Equal(t, []string{"database", "get_chain_id", "history", "network_broadcast", "lookup_asset_symbols"}, methods)
+}
+
 func TestClient_Transfer(t *testing.T) {
-	client, err := NewClient(testNet)
+	server := newServer(t)
+	client, err := NewClient(server.URL)
 	require.Nil(t, err)
 
 	cali4888arr, err := client.Database.LookupAccounts("cali4889", 2)
@@ -53,10 +78,42 @@ func TestClient_Transfer(t *testing.T) {
 	}
 
 	require.NoError(t, client.Transfer(cali4889IDActiveKey, from, to, amount, fee))
+	require.Equal(t, "broadcast_transaction", lastCall(server).Method)
+}
+
+func TestClient_Signer(t *testing.T) {
+	server := newServer(t)
+	from := types.MustParseObjectID("1.2.1144")
+	to := types.MustParseObjectID("1.2.1145")
+	amount := types.AssetAmount{AssetID: types.MustParseObjectID("1.3.0"), Amount: 1000}
+	fee := types.AssetAmount{AssetID: types.MustParseObjectID("1.3.0")}
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// an invalid key
+	require.Error(t, client.Transfer("", from, to, amount, fee))
+
+	signer, err := sign.NewWIFSigner("5JiTY3m9u1iPfoKsZdn18pnf26XvX2WnXFJckSiSaiUniNVzxLn")
+	require.NoError(t, err)
+	require.NoError(t, client.TransferWith(context.Background(), signer, from, to, amount, fee))
+
+	call := lastCall(server)
+	require.Equal(t, "broadcast_transaction", call.Method)
+	var tx types.Transaction
+	require.NoError(t, json.Unmarshal(call.Params[0], &tx))
+	require.NoError(t, sign.NewSignedTransaction(&tx).Verify(testChainID, signer.PublicKey()))
+}
+
+func lastCall(server *nodetest.Server) nodetest.Call {
+	calls := server.Calls()
+	return calls[len(calls)-1]
 }
 
 func TestClient_LimitOrderCreate(t *testing.T) {
-	client, err := NewClient(testNet)
+	server := newServer(t)
+	client, err := NewClient(server.URL)
 	require.Nil(t, err)
 
 	cali4889arr, err := client.Database.LookupAccounts("cali4889", 1)
@@ -90,7 +147,141 @@ func TestClient_LimitOrderCreate(t *testing.T) {
 	require.NoError(t, err)
 
 	orderID := types.MustParseObjectID(id)
+	require.Equal(t, "1.7.1032", orderID.String())
 
 	err = client.LimitOrderCancel(cali4889IDActiveKey, cali4889ID, orderID, fee)
 	require.NoError(t, err)
+	require.Equal(t, "broadcast_transaction", lastCall(server).Method)
+}
+
+func TestClient_Replay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replay")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	recording := filepath.Join(dir, "session.jsonl")
+
+	limitOrderCreate := func(client *Client) string {
+		fee := types.AssetAmount{AssetID: types.MustParseObjectID("1.3.0")}
+		amSell := types.AssetAmount{Amount: 100, AssetID: types.MustParseObjectID("1.3.0")}
+		minBuy := types.AssetAmount{Amount: 10, AssetID: types.MustParseObjectID("1.3.1")}
+		seller := types.MustParseObjectID("1.2.1144")
+
+		id, err := client.LimitOrderCreate("5JiTY3m9u1iPfoKsZdn18pnf26XvX2WnXFJckSiSaiUniNVzxLn", seller, fee, amSell, minBuy, time.Hour, false)
+		require.NoError(t, err)
+		return id
+	}
+
+	// record the session against the node
+	transport, err := websocket.NewTransport(newServer(t).URL)
+	require.NoError(t, err)
+	recorder, err := replay.Record(transport, recording)
+	require.NoError(t, err)
+
+	client, err := NewClientWithCaller(recorder)
+	require.NoError(t, err)
+	recorded := limitOrderCreate(client)
+	require.NoError(t, client.Close())
+
+	// replay it offline, the signed transaction has to match the recorded one
+	replayer, err := replay.Replay(recording)
+	require.NoError(t, err)
+
+	client, err = NewClientWithCaller(replayer)
+	require.NoError(t, err)
+	require.Equal(t, recorded, limitOrderCreate(client))
+	require.Empty(t, replayer.Unused())
+}
+
+func TestClient_Options(t *testing.T) {
+	t.Run("credentials", func(t *testing.T) {
+		server := newServer(t)
+		client, err := NewClient(server.URL, WithCredentials("user", "password"))
+		require.NoError(t, err)
+		defer client.Close()
+
+		login := server.Calls()[0]
+		require.Equal(t, "login", login.Method)
+		require.Equal(t, []json.RawMessage{json.RawMessage(`"user"`), json.RawMessage(`"password"`)}, login.Params)
+	})
+
+	t.Run("lazy APIs", func(t *testing.T) {
+		server := newServer(t)
+		server.RespondError(nodetest.LoginAPI, "history", "Assert Exception: history API is disabled")
+
+		_, err := NewClient(server.URL)
+		require.Error(t, err)
+
+		client, err := NewClient(server.URL, WithAPIs(NetworkBroadcastAPI))
+		require.NoError(t, err)
+		defer client.Close()
+
+		_, err = client.History.GetMarketHistoryBuckets()
+		require.Error(t, err)
+	})
+
+	t.Run("chain ID", func(t *testing.T) {
+		_, err := NewClient(newServer(t).URL, WithChainID("other"))
+		require.Equal(t, ErrChainIDMismatch, errors.Cause(err))
+	})
+
+	t.Run("caller", func(t *testing.T) {
+		transport, err := websocket.NewTransport(newServer(t).URL)
+		require.NoError(t, err)
+
+		client, err := NewClient("", WithCaller(transport))
+		require.NoError(t, err)
+		require.NoError(t, client.Close())
+	})
+}
+
+func TestClient_Batch(t *testing.T) {
+	server := newServer(t)
+	server.Respond("history", "get_market_history_buckets", []int{15, 60})
+
+	client, err := NewClient(server.URL, WithAPIs())
+	require.NoError(t, err)
+	defer client.Close()
+
+	var test, fakeUSD []database.Asset
+	var buckets []int
+	var failed *caller.BatchRequest
+	err = client.Batch(func(b *Batch) {
+		b.Database("lookup_asset_symbols", []interface{}{[]string{"TEST"}}, &test)
+		b.Database("lookup_asset_symbols", []interface{}{[]string{"PEG.FAKEUSD"}}, &fakeUSD)
+		b.History("get_market_history_buckets", caller.EmptyParams, &buckets)
+		failed = b.Database("get_unknown", caller.EmptyParams, nil)
+	})
+	require.Error(t, err)
+	require.Equal(t, err, failed.Err)
+
+	require.Equal(t, "1.3.0", test[0].ID.String())
+	require.Equal(t, "1.3.1", fakeUSD[0].ID.String())
+	require.Equal(t, []int{15, 60}, buckets)
+}
+
+func TestClient_Batch_Interceptors(t *testing.T) {
+	server := newServer(t)
+
+	var mutex sync.Mutex
+	var methods []string
+	observe := caller.Observe(func(call caller.Call) {
+		mutex.Lock()
+		methods = append(methods, call.Method)
+		mutex.Unlock()
+	})
+
+	client, err := NewClient(server.URL, WithAPIs(), WithInterceptors(observe))
+	require.NoError(t, err)
+	defer client.Close()
+
+	// the batch is sent by the websocket transport through the interceptors
+	methods = nil
+	var test, fakeUSD []database.Asset
+	require.NoError(t, client.Batch(func(b *Batch) {
+		b.Database("lookup_asset_symbols", []interface{}{[]string{"TEST"}}, &test)
+		b.Database("lookup_asset_symbols", []interface{}{[]string{"PEG.FAKEUSD"}}, &fakeUSD)
+	}))
+	require.Equal(t, "1.3.0", test[0].ID.String())
+	require.Equal(t, "1.3.1", fakeUSD[0].ID.String())
+	require.Equal(t, []string{"lookup_asset_symbols", "lookup_asset_symbols"}, methods)
 }
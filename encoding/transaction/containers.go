@@ -0,0 +1,147 @@
+package transaction
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Void is fc::void_t, it has no serialized form.
+// The future_extensions of Graphene is a static_variant of Void only.
+type Void struct{}
+
+// MarshalTransaction implements TransactionMarshaller
+func (Void) MarshalTransaction(*Encoder) error { return nil }
+
+// UnmarshalTransaction implements TransactionUnmarshaller
+func (*Void) UnmarshalTransaction(*Decoder) error { return nil }
+
+// Lesser is implemented by the flat_set elements which are not ordered like their serialized form,
+// e.g. an object ID: its instance is a varint, so 300 (ac02) would go before 200 (c801)
+type Lesser interface {
+	// Less reports whether the element goes before the other element of the same type
+	Less(other interface{}) bool
+}
+
+// EncodeOptional encodes optional<T>: nil, including a nil pointer, is encoded as absent,
+// a non nil pointer is dereferenced
+func (encoder *Encoder) EncodeOptional(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return encoder.EncodeBool(false)
+	}
+	if err := encoder.EncodeBool(true); err != nil {
+		return err
+	}
+	return encoder.Encode(v)
+}
+
+// EncodeBytes encodes vector<char>: the length followed by the bytes
+func (encoder *Encoder) EncodeBytes(b []byte) error {
+	if err := encoder.EncodeUVarint(uint64(len(b))); err != nil {
+		return err
+	}
+	return encoder.writeBytes(b)
+}
+
+// EncodeFixedBytes encodes fc::array<char, N>, b has to be exactly n bytes long
+func (encoder *Encoder) EncodeFixedBytes(b []byte, n int) error {
+	if len(b) != n {
+		return errors.Errorf("encoder: expected %d bytes, got %d", n, len(b))
+	}
+	return encoder.writeBytes(b)
+}
+
+// EncodeStaticVariant encodes static_variant: the tag, i.e. the index of the type in the variant,
+// followed by the value
+func (encoder *Encoder) EncodeStaticVariant(tag uint64, v interface{}) error {
+	if err := encoder.EncodeUVarint(tag); err != nil {
+		return err
+	}
+	return encoder.Encode(v)
+}
+
+// EncodeFlatMap encodes flat_map<K, V>, v has to be a map with integer or string keys.
+// The pairs are written in the order of the keys.
+func (encoder *Encoder) EncodeFlatMap(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map {
+		return errors.Errorf("encoder: flat_map expects a map, got %T", v)
+	}
+	return encoder.encodeMap(rv)
+}
+
+// EncodeFlatSet encodes flat_set<T>, v has to be a slice or an array. The elements are written sorted by less,
+// which gets the indexes of the elements like sort.Slice. When less is nil, Lesser elements are sorted by their
+// Less method, integers and strings in the natural order and other types by their serialized form,
+// which is their order only if they have a fixed size like the public keys.
+// Duplicates are rejected, the node would drop them and the signature would not match.
+func (encoder *Encoder) EncodeFlatSet(v interface{}, less func(i, j int) bool) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return errors.Errorf("encoder: flat_set expects a slice, got %T", v)
+	}
+	return encoder.encodeSet(rv, less)
+}
+
+func (encoder *Encoder) encodeSet(v reflect.Value, less func(i, j int) bool) error {
+	n := v.Len()
+
+	// sort the indexes, the passed value is left as is
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+
+	if less == nil {
+		var err error
+		if less, err = setOrder(v); err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return less(order[i], order[j]) })
+
+	for i := 1; i < n; i++ {
+		if !less(order[i-1], order[i]) {
+			return errors.Errorf("encoder: duplicate %s element %d in flat_set", v.Type().Elem(), order[i])
+		}
+	}
+
+	if err := encoder.EncodeUVarint(uint64(n)); err != nil {
+		return err
+	}
+	for _, i := range order {
+		if err := encoder.encodeValue(v.Index(i)); err != nil {
+			return errors.Wrapf(err, "encoder: failed to encode %s element %d", v.Type(), i)
+		}
+	}
+	return nil
+}
+
+// setOrder returns the default order of the flat_set elements
+func setOrder(v reflect.Value) (func(i, j int) bool, error) {
+	if v.Type().Elem().Implements(reflect.TypeOf((*Lesser)(nil)).Elem()) {
+		return func(i, j int) bool { return v.Index(i).Interface().(Lesser).Less(v.Index(j).Interface()) }, nil
+	}
+
+	switch v.Type().Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(i, j int) bool { return v.Index(i).Int() < v.Index(j).Int() }, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(i, j int) bool { return v.Index(i).Uint() < v.Index(j).Uint() }, nil
+	case reflect.String:
+		return func(i, j int) bool { return v.Index(i).String() < v.Index(j).String() }, nil
+	}
+
+	encoded := make([][]byte, v.Len())
+	for i := range encoded {
+		var b bytes.Buffer
+		if err := NewEncoder(&b).encodeValue(v.Index(i)); err != nil {
+			return nil, errors.Wrapf(err, "encoder: failed to encode %s element %d", v.Type(), i)
+		}
+		encoded[i] = b.Bytes()
+	}
+	return func(i, j int) bool { return bytes.Compare(encoded[i], encoded[j]) < 0 }, nil
+}
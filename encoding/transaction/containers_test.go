@@ -0,0 +1,184 @@
+package transaction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// varint is a Lesser serialized as a varint
+type varint struct{ value uint64 }
+
+func (v varint) Less(other interface{}) bool { return v.value < other.(varint).value }
+
+func (v varint) MarshalTransaction(encoder *Encoder) error { return encoder.EncodeUVarint(v.value) }
+
+// The expected values are the fc::raw::pack serialization of the commented C++ values
+func TestEncoder_Containers(t *testing.T) {
+	value := uint16(0x0102)
+
+	type authority struct {
+		Accounts []uint32 `transaction:"flat_set"`
+	}
+
+	tests := []struct {
+		name     string
+		encode   func(encoder *Encoder) error
+		expected string
+	}{
+		{
+			// optional<uint16_t>(0x0102)
+			name:     "optional",
+			encode:   func(encoder *Encoder) error { return encoder.EncodeOptional(&value) },
+			expected: "010201",
+		},
+		{
+			// optional<uint16_t>()
+			name:     "empty optional",
+			encode:   func(encoder *Encoder) error { return encoder.EncodeOptional((*uint16)(nil)) },
+			expected: "00",
+		},
+		{
+			// flat_set<uint16_t>{3, 1, 2}
+			name:     "flat_set of integers",
+			encode:   func(encoder *Encoder) error { return encoder.EncodeFlatSet([]uint16{3, 1, 2}, nil) },
+			expected: "03010002000300",
+		},
+		{
+			// flat_set<string>{"b", "a"}
+			name:     "flat_set of strings",
+			encode:   func(encoder *Encoder) error { return encoder.EncodeFlatSet([]string{"b", "a"}, nil) },
+			expected: "0201610162",
+		},
+		{
+			// flat_set<fc::array<char, 2>>{{2, 0}, {1, 5}}
+			name:     "flat_set of arrays",
+			encode:   func(encoder *Encoder) error { return encoder.EncodeFlatSet([][2]byte{{2, 0}, {1, 5}}, nil) },
+			expected: "0201050200",
+		},
+		{
+			// flat_set<varint>{300, 200}, sorted by the values, 200 is c801 and 300 is ac02
+			name:     "flat_set of lessers",
+			encode:   func(encoder *Encoder) error { return encoder.EncodeFlatSet([]varint{{300}, {200}}, nil) },
+			expected: "02c801ac02",
+		},
+		{
+			// flat_set<uint32_t>{5, 1} as a struct field
+			name:     "flat_set field",
+			encode:   func(encoder *Encoder) error { return encoder.Encode(authority{Accounts: []uint32{5, 1}}) },
+			expected: "020100000005000000",
+		},
+		{
+			// flat_map<string, uint8_t>{{"b", 2}, {"a", 1}}
+			name:     "flat_map",
+			encode:   func(encoder *Encoder) error { return encoder.EncodeFlatMap(map[string]uint8{"b": 2, "a": 1}) },
+			expected: "02016101016202",
+		},
+		{
+			// static_variant<int64_t, string>(string("ab"))
+			name:     "static_variant",
+			encode:   func(encoder *Encoder) error { return encoder.EncodeStaticVariant(1, "ab") },
+			expected: "01026162",
+		},
+		{
+			// fc::array<char, 4>{0xde, 0xad, 0xbe, 0xef}
+			name:     "array",
+			encode:   func(encoder *Encoder) error { return encoder.EncodeFixedBytes([]byte{0xde, 0xad, 0xbe, 0xef}, 4) },
+			expected: "deadbeef",
+		},
+		{
+			// vector<char>{0xde, 0xad}
+			name:     "vector<char>",
+			encode:   func(encoder *Encoder) error { return encoder.EncodeBytes([]byte{0xde, 0xad}) },
+			expected: "02dead",
+		},
+		{
+			// static_variant<void_t>(void_t())
+			name:     "void_t",
+			encode:   func(encoder *Encoder) error { return encoder.EncodeStaticVariant(0, Void{}) },
+			expected: "00",
+		},
+		{
+			// extensions_type()
+			name:     "extensions",
+			encode:   func(encoder *Encoder) error { return encoder.EncodeFlatSet([]Void{}, nil) },
+			expected: "00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			require.NoError(t, tt.encode(NewEncoder(&b)))
+			require.Equal(t, tt.expected, hex.EncodeToString(b.Bytes()))
+		})
+	}
+}
+
+func TestEncoder_Containers_Errors(t *testing.T) {
+	var b bytes.Buffer
+	encoder := NewEncoder(&b)
+
+	require.Error(t, encoder.EncodeFixedBytes([]byte{1, 2, 3}, 4))
+	require.Error(t, encoder.EncodeFlatSet([]uint16{1, 2, 1}, nil))
+	require.Error(t, encoder.EncodeFlatSet(uint16(1), nil))
+	require.Error(t, encoder.EncodeFlatMap([]uint16{1}))
+
+	// a custom order
+	b.Reset()
+	ids := []uint64{300, 2}
+	require.NoError(t, encoder.EncodeFlatSet(ids, func(i, j int) bool { return ids[i] > ids[j] }))
+	require.Equal(t, "022c010000000000000200000000000000", hex.EncodeToString(b.Bytes()))
+}
+
+func TestDecoder_Containers_RoundTrip(t *testing.T) {
+	var b bytes.Buffer
+	encoder := NewEncoder(&b)
+	require.NoError(t, encoder.EncodeOptional("memo"))
+	require.NoError(t, encoder.EncodeStaticVariant(0, Void{}))
+	require.NoError(t, encoder.EncodeFixedBytes([]byte{1, 2}, 2))
+
+	decoder := NewDecoder(&b)
+	var memo string
+	present, err := decoder.DecodeOptional(&memo)
+	require.NoError(t, err)
+	require.True(t, present)
+	require.Equal(t, "memo", memo)
+
+	tag, err := decoder.DecodeStaticVariant()
+	require.NoError(t, err)
+	require.Zero(t, tag)
+	require.NoError(t, decoder.Decode(&Void{}))
+
+	fixed, err := decoder.ReadBytes(2)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2}, fixed)
+}
+
+func TestEncoder_Extension(t *testing.T) {
+	type extension struct {
+		First  *uint16
+		Second *string
+		Third  []uint8
+	}
+
+	value := "ab"
+	ext := extension{Second: &value, Third: []uint8{1}}
+
+	// extension<T>: the number of the present fields, then the index and the value of each of them
+	var b bytes.Buffer
+	require.NoError(t, NewEncoder(&b).EncodeExtension(ext))
+	require.Equal(t, "02"+"01026162"+"020101", hex.EncodeToString(b.Bytes()))
+
+	var decoded extension
+	require.NoError(t, NewDecoder(bytes.NewReader(b.Bytes())).DecodeExtension(&decoded))
+	require.Equal(t, ext, decoded)
+
+	// the fields have to be optional
+	require.Error(t, NewEncoder(&b).EncodeExtension(struct{ Value uint16 }{}))
+
+	// the indexes have to be increasing
+	require.Error(t, NewDecoder(bytes.NewReader([]byte{2, 1, 2, 0x61, 0x62, 1, 2, 0x61, 0x62})).DecodeExtension(&decoded))
+}
@@ -0,0 +1,234 @@
+package transaction
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// MaxLength is the largest length of a vector or a byte sequence the Decoder accepts,
+// it is MAX_ARRAY_ALLOC_SIZE of fc
+const MaxLength = 10 << 20
+
+// readChunk is the size of the chunks a long byte sequence is read in,
+// so the memory is allocated as the data arrives rather than as the length claims
+const readChunk = 64 << 10
+
+// Decoder reads the values written by the Encoder
+type Decoder struct {
+	r io.Reader
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r}
+}
+
+// ReadByte implements io.ByteReader, the varints are read byte by byte
+// so the decoder never consumes more than the value being decoded
+func (decoder *Decoder) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(decoder.r, b[:]); err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (decoder *Decoder) DecodeVarint() (int64, error) {
+	i, err := binary.ReadVarint(decoder)
+	if err != nil {
+		return 0, errors.Wrap(err, "decoder: failed to read varint")
+	}
+	return i, nil
+}
+
+func (decoder *Decoder) DecodeUVarint() (uint64, error) {
+	i, err := binary.ReadUvarint(decoder)
+	if err != nil {
+		return 0, errors.Wrap(err, "decoder: failed to read uvarint")
+	}
+	return i, nil
+}
+
+func (decoder *Decoder) DecodeLittleEndianUInt64() (uint64, error) {
+	b, err := decoder.readBytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+func (decoder *Decoder) DecodeLittleEndianUInt32() (uint32, error) {
+	b, err := decoder.readBytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+// DecodeNumber reads a fixed size little-endian number into v, e.g. *uint16
+func (decoder *Decoder) DecodeNumber(v interface{}) error {
+	if err := binary.Read(decoder.r, binary.LittleEndian, v); err != nil {
+		return errors.Wrapf(err, "decoder: failed to read number %T", v)
+	}
+	return nil
+}
+
+func (decoder *Decoder) DecodeBool() (bool, error) {
+	b, err := decoder.ReadByte()
+	if err != nil {
+		return false, errors.Wrap(err, "decoder: failed to read bool")
+	}
+	switch b {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	default:
+		return false, errors.Errorf("decoder: invalid bool value %d", b)
+	}
+}
+
+// DecodeString reads a string prefixed with its length
+func (decoder *Decoder) DecodeString() (string, error) {
+	b, err := decoder.DecodeBytes()
+	return string(b), err
+}
+
+// DecodeBytes reads a byte vector prefixed with its length
+func (decoder *Decoder) DecodeBytes() ([]byte, error) {
+	n, err := decoder.DecodeLength()
+	if err != nil {
+		return nil, err
+	}
+	return decoder.ReadBytes(n)
+}
+
+// DecodeLength reads the length of a vector or a byte sequence. The length has to be within MaxLength and,
+// if the reader reports the number of the unread bytes like bytes.Reader does, within the remaining data:
+// every element takes at least one byte, except void_t which is never a vector element on the chain.
+func (decoder *Decoder) DecodeLength() (int, error) {
+	n, err := decoder.DecodeUVarint()
+	if err != nil {
+		return 0, err
+	}
+	if n > MaxLength {
+		return 0, errors.Errorf("decoder: length %d exceeds the limit of %d", n, MaxLength)
+	}
+	if r, ok := decoder.r.(interface{ Len() int }); ok && n > uint64(r.Len()) {
+		return 0, errors.Errorf("decoder: length %d exceeds the remaining %d bytes", n, r.Len())
+	}
+	return int(n), nil
+}
+
+// ReadBytes reads the given number of raw bytes, e.g. a public key
+func (decoder *Decoder) ReadBytes(n int) ([]byte, error) {
+	return decoder.readBytes(n)
+}
+
+// DecodeOptional reads the presence flag and decodes v if the value is present
+func (decoder *Decoder) DecodeOptional(v interface{}) (bool, error) {
+	present, err := decoder.DecodeBool()
+	if err != nil || !present {
+		return false, err
+	}
+	return true, decoder.Decode(v)
+}
+
+// DecodeVector reads the length of the vector and calls decodeElement for every element
+func (decoder *Decoder) DecodeVector(decodeElement func(i int) error) error {
+	n, err := decoder.DecodeLength()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := decodeElement(i); err != nil {
+			return errors.Wrapf(err, "decoder: failed to read element #%d", i)
+		}
+	}
+	return nil
+}
+
+// DecodeStaticVariant reads the tag of a static variant, the value is decoded by the caller
+func (decoder *Decoder) DecodeStaticVariant() (uint64, error) {
+	return decoder.DecodeUVarint()
+}
+
+// Decode reads the value into v, which has to be a pointer to a number, bool, string,
+// byte slice, TransactionUnmarshaller or a slice of them
+func (decoder *Decoder) Decode(v interface{}) error {
+	if unmarshaller, ok := v.(TransactionUnmarshaller); ok {
+		return unmarshaller.UnmarshalTransaction(decoder)
+	}
+
+	switch v := v.(type) {
+	case *int8, *int16, *int32, *int64, *uint8, *uint16, *uint32, *uint64:
+		return decoder.DecodeNumber(v)
+
+	case *bool:
+		b, err := decoder.DecodeBool()
+		*v = b
+		return err
+
+	case *string:
+		s, err := decoder.DecodeString()
+		*v = s
+		return err
+
+	case *[]byte:
+		b, err := decoder.DecodeBytes()
+		*v = b
+		return err
+
+	default:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Slice {
+			return decoder.decodeSlice(rv.Elem())
+		}
+		return errors.Errorf("decoder: unsupported type %T encountered", v)
+	}
+}
+
+// decodeSlice decodes a vector, every element is decoded with Decode.
+// The slice grows as the elements are decoded, the length alone never allocates the memory.
+func (decoder *Decoder) decodeSlice(v reflect.Value) error {
+	n, err := decoder.DecodeLength()
+	if err != nil {
+		return err
+	}
+	capacity := n
+	if capacity > readChunk {
+		capacity = readChunk
+	}
+	slice := reflect.MakeSlice(v.Type(), 0, capacity)
+	for i := 0; i < n; i++ {
+		element := reflect.New(v.Type().Elem())
+		if err := decoder.Decode(element.Interface()); err != nil {
+			return errors.Wrapf(err, "decoder: failed to read element #%d", i)
+		}
+		slice = reflect.Append(slice, element.Elem())
+	}
+	v.Set(slice)
+	return nil
+}
+
+func (decoder *Decoder) readBytes(n int) ([]byte, error) {
+	if n < 0 || n > MaxLength {
+		return nil, errors.Errorf("decoder: invalid length %d", n)
+	}
+	if n <= readChunk {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(decoder.r, b); err != nil {
+			return nil, errors.Wrapf(err, "decoder: failed to read %d bytes", n)
+		}
+		return b, nil
+	}
+
+	var b bytes.Buffer
+	if _, err := io.CopyN(&b, decoder.r, int64(n)); err != nil {
+		return nil, errors.Wrapf(err, "decoder: failed to read %d bytes", n)
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,127 @@
+package transaction
+
+// RollingDecoder is a Decoder keeping the first error, the subsequent calls are no-ops
+// returning the zero values, so a sequence of fields can be decoded without checking every error
+type RollingDecoder struct {
+	next *Decoder
+	err  error
+}
+
+func NewRollingDecoder(next *Decoder) *RollingDecoder {
+	return &RollingDecoder{next, nil}
+}
+
+func (decoder *RollingDecoder) DecodeVarint() int64 {
+	var i int64
+	if decoder.err == nil {
+		i, decoder.err = decoder.next.DecodeVarint()
+	}
+	return i
+}
+
+func (decoder *RollingDecoder) DecodeUVarint() uint64 {
+	var i uint64
+	if decoder.err == nil {
+		i, decoder.err = decoder.next.DecodeUVarint()
+	}
+	return i
+}
+
+func (decoder *RollingDecoder) DecodeNumber(v interface{}) {
+	if decoder.err == nil {
+		decoder.err = decoder.next.DecodeNumber(v)
+	}
+}
+
+func (decoder *RollingDecoder) DecodeBool() bool {
+	var b bool
+	if decoder.err == nil {
+		b, decoder.err = decoder.next.DecodeBool()
+	}
+	return b
+}
+
+func (decoder *RollingDecoder) DecodeString() string {
+	var s string
+	if decoder.err == nil {
+		s, decoder.err = decoder.next.DecodeString()
+	}
+	return s
+}
+
+func (decoder *RollingDecoder) DecodeBytes() []byte {
+	var b []byte
+	if decoder.err == nil {
+		b, decoder.err = decoder.next.DecodeBytes()
+	}
+	return b
+}
+
+func (decoder *RollingDecoder) ReadBytes(n int) []byte {
+	var b []byte
+	if decoder.err == nil {
+		b, decoder.err = decoder.next.ReadBytes(n)
+	}
+	return b
+}
+
+func (decoder *RollingDecoder) DecodeOptional(v interface{}) bool {
+	var present bool
+	if decoder.err == nil {
+		present, decoder.err = decoder.next.DecodeOptional(v)
+	}
+	return present
+}
+
+func (decoder *RollingDecoder) DecodeVector(decodeElement func(i int) error) {
+	if decoder.err == nil {
+		decoder.err = decoder.next.DecodeVector(decodeElement)
+	}
+}
+
+func (decoder *RollingDecoder) DecodeStaticVariant() uint64 {
+	var tag uint64
+	if decoder.err == nil {
+		tag, decoder.err = decoder.next.DecodeStaticVariant()
+	}
+	return tag
+}
+
+func (decoder *RollingDecoder) DecodeExtension(v interface{}) {
+	if decoder.err == nil {
+		decoder.err = decoder.next.DecodeExtension(v)
+	}
+}
+
+func (decoder *RollingDecoder) Decode(v interface{}) {
+	if decoder.err == nil {
+		decoder.err = decoder.next.Decode(v)
+	}
+}
+
+func (decoder *RollingDecoder) DecodeLittleEndianUInt64() uint64 {
+	var i uint64
+	if decoder.err == nil {
+		i, decoder.err = decoder.next.DecodeLittleEndianUInt64()
+	}
+	return i
+}
+
+func (decoder *RollingDecoder) DecodeLittleEndianUInt32() uint32 {
+	var i uint32
+	if decoder.err == nil {
+		i, decoder.err = decoder.next.DecodeLittleEndianUInt32()
+	}
+	return i
+}
+
+// Fail sets the error unless an error has already occurred
+func (decoder *RollingDecoder) Fail(err error) {
+	if decoder.err == nil {
+		decoder.err = err
+	}
+}
+
+func (decoder *RollingDecoder) Err() error {
+	return decoder.err
+}
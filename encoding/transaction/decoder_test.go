@@ -0,0 +1,112 @@
+package transaction
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_RoundTrip(t *testing.T) {
+	var b bytes.Buffer
+	encoder := NewEncoder(&b)
+	require.NoError(t, encoder.EncodeVarint(-300))
+	require.NoError(t, encoder.EncodeUVarint(300))
+	require.NoError(t, encoder.EncodeLittleEndianUInt64(1<<40+7))
+	require.NoError(t, encoder.EncodeLittleEndianUInt32(1<<20+7))
+	require.NoError(t, encoder.EncodeBool(true))
+	require.NoError(t, encoder.Encode(uint16(12345)))
+	require.NoError(t, encoder.Encode(int32(-5)))
+	require.NoError(t, encoder.Encode("memo"))
+
+	decoder := NewDecoder(&b)
+
+	varint, err := decoder.DecodeVarint()
+	require.NoError(t, err)
+	require.Equal(t, int64(-300), varint)
+
+	uvarint, err := decoder.DecodeUVarint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(300), uvarint)
+
+	u64, err := decoder.DecodeLittleEndianUInt64()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1<<40+7), u64)
+
+	u32, err := decoder.DecodeLittleEndianUInt32()
+	require.NoError(t, err)
+	require.Equal(t, uint32(1<<20+7), u32)
+
+	flag, err := decoder.DecodeBool()
+	require.NoError(t, err)
+	require.True(t, flag)
+
+	var u16 uint16
+	require.NoError(t, decoder.Decode(&u16))
+	require.Equal(t, uint16(12345), u16)
+
+	var i32 int32
+	require.NoError(t, decoder.Decode(&i32))
+	require.Equal(t, int32(-5), i32)
+
+	var s string
+	require.NoError(t, decoder.Decode(&s))
+	require.Equal(t, "memo", s)
+
+	// nothing is left
+	_, err = decoder.ReadByte()
+	require.Error(t, err)
+}
+
+func TestDecoder_Containers(t *testing.T) {
+	data := []byte{
+		1, 4, 't', 'e', 's', 't', // optional string
+		0,          // absent optional
+		2, 1, 2, 3, // vector of 2 varints: 1, 2 and a trailing static variant tag 3
+	}
+	decoder := NewRollingDecoder(NewDecoder(bytes.NewReader(data)))
+
+	var present, absent string
+	require.True(t, decoder.DecodeOptional(&present))
+	require.Equal(t, "test", present)
+	require.False(t, decoder.DecodeOptional(&absent))
+
+	var elements []uint64
+	decoder.DecodeVector(func(i int) error {
+		elements = append(elements, decoder.DecodeUVarint())
+		return decoder.Err()
+	})
+	require.Equal(t, []uint64{1, 2}, elements)
+	require.Equal(t, uint64(3), decoder.DecodeStaticVariant())
+	require.NoError(t, decoder.Err())
+
+	// the first error is kept
+	require.Equal(t, uint64(0), decoder.DecodeUVarint())
+	require.Error(t, decoder.Err())
+
+	_, err := NewDecoder(bytes.NewReader([]byte{2})).DecodeBool()
+	require.Error(t, err)
+}
+
+func TestDecoder_Length(t *testing.T) {
+	// a length close to the int range
+	huge := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+
+	_, err := NewDecoder(bytes.NewReader(huge)).DecodeBytes()
+	require.Error(t, err)
+
+	var numbers []uint64
+	require.Error(t, NewDecoder(bytes.NewReader(huge)).Decode(&numbers))
+	require.Error(t, NewDecoder(bytes.NewReader(huge)).DecodeVector(func(int) error { return nil }))
+
+	// the length has to fit into the remaining data
+	_, err = NewDecoder(bytes.NewReader([]byte{3, 1, 2})).DecodeBytes()
+	require.Error(t, err)
+
+	// the length within MaxLength is read as the data arrives when the reader doesn't tell the remaining length
+	claimed := []byte{0x80, 0x80, 0x80, 0x04} // 8MB
+	_, err = NewDecoder(io.MultiReader(bytes.NewReader(claimed), bytes.NewReader([]byte{1, 2}))).DecodeBytes()
+	require.Error(t, err)
+	require.Error(t, NewDecoder(io.MultiReader(bytes.NewReader(claimed), bytes.NewReader([]byte{1, 2}))).Decode(&numbers))
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/pkg/errors"
 	"io"
 	"math"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
@@ -63,6 +64,8 @@ func (encoder *Encoder) EncodeBool(b bool) error {
 	}
 }
 
+// Encode writes the value in the Graphene binary format. TransactionMarshaller implementations are used when present,
+// otherwise the value is encoded by reflection, see encodeValue.
 func (encoder *Encoder) Encode(v interface{}) error {
 	if marshaller, ok := v.(TransactionMarshaller); ok {
 		return marshaller.MarshalTransaction(encoder)
@@ -95,7 +98,7 @@ func (encoder *Encoder) Encode(v interface{}) error {
 		return encoder.encodeString(v)
 
 	default:
-		return errors.Errorf("encoder: unsupported type (%+v) encountered", v)
+		return encoder.encodeReflect(reflect.ValueOf(v))
 	}
 }
 
@@ -0,0 +1,210 @@
+package transaction
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// tagName is the name of the struct tag controlling the encoding of a field:
+// `transaction:"-"` excludes the field from the serialized form,
+// `transaction:"flat_set"` encodes the slice as a flat_set, see EncodeFlatSet
+const tagName = "transaction"
+
+var (
+	marshallerType = reflect.TypeOf((*TransactionMarshaller)(nil)).Elem()
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// encodeReflect encodes the value passed to Encode. A pointer on the top level is dereferenced,
+// nested pointers are encoded as optionals.
+func (encoder *Encoder) encodeReflect(v reflect.Value) error {
+	if !v.IsValid() {
+		return errors.New("encoder: nil encountered")
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return errors.Errorf("encoder: nil %s encountered", v.Type())
+		}
+		v = v.Elem()
+	} else if !v.CanAddr() {
+		// make the value addressable to use the pointer receiver marshallers
+		addressable := reflect.New(v.Type()).Elem()
+		addressable.Set(v)
+		v = addressable
+	}
+	return encoder.encodeValue(v)
+}
+
+// encodeValue encodes the value using the Graphene serialization rules:
+// structs are encoded field by field in the declaration order, pointers are optionals,
+// slices and maps are prefixed with the varint length, arrays have a fixed length.
+func (encoder *Encoder) encodeValue(v reflect.Value) error {
+	if marshaller, ok := asMarshaller(v); ok {
+		return marshaller.MarshalTransaction(encoder)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return encoder.EncodeBool(false)
+		}
+		if err := encoder.EncodeBool(true); err != nil {
+			return err
+		}
+		return encoder.encodeValue(v.Elem())
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return errors.Errorf("encoder: nil %s encountered", v.Type())
+		}
+		return encoder.encodeReflect(v.Elem())
+
+	case reflect.Bool:
+		return encoder.EncodeBool(v.Bool())
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return encoder.encodeInt(v)
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return encoder.encodeUint(v)
+
+	case reflect.String:
+		return encoder.encodeString(v.String())
+
+	case reflect.Slice:
+		if v.Type() == rawMessageType {
+			return errors.New("encoder: json.RawMessage can't be encoded")
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return encoder.EncodeBytes(v.Bytes())
+		}
+		if err := encoder.EncodeUVarint(uint64(v.Len())); err != nil {
+			return err
+		}
+		return encoder.encodeElements(v)
+
+	case reflect.Array:
+		return encoder.encodeElements(v)
+
+	case reflect.Map:
+		return encoder.encodeMap(v)
+
+	case reflect.Struct:
+		return encoder.encodeStruct(v)
+
+	default:
+		return errors.Errorf("encoder: unsupported type %s encountered", v.Type())
+	}
+}
+
+// encodeInt encodes a fixed size signed integer, keeping its size
+func (encoder *Encoder) encodeInt(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Int8:
+		return encoder.EncodeNumber(int8(v.Int()))
+	case reflect.Int16:
+		return encoder.EncodeNumber(int16(v.Int()))
+	case reflect.Int32:
+		return encoder.EncodeNumber(int32(v.Int()))
+	default:
+		return encoder.EncodeNumber(v.Int())
+	}
+}
+
+// encodeUint encodes a fixed size unsigned integer, keeping its size
+func (encoder *Encoder) encodeUint(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Uint8:
+		return encoder.EncodeNumber(uint8(v.Uint()))
+	case reflect.Uint16:
+		return encoder.EncodeNumber(uint16(v.Uint()))
+	case reflect.Uint32:
+		return encoder.EncodeNumber(uint32(v.Uint()))
+	default:
+		return encoder.EncodeNumber(v.Uint())
+	}
+}
+
+func (encoder *Encoder) encodeElements(v reflect.Value) error {
+	for i := 0; i < v.Len(); i++ {
+		if err := encoder.encodeValue(v.Index(i)); err != nil {
+			return errors.Wrapf(err, "encoder: failed to encode %s element %d", v.Type(), i)
+		}
+	}
+	return nil
+}
+
+// encodeMap encodes the map as a flat_map: the pairs are sorted by the keys
+func (encoder *Encoder) encodeMap(v reflect.Value) error {
+	keys := v.MapKeys()
+
+	var less func(i, j int) bool
+	switch v.Type().Key().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = func(i, j int) bool { return keys[i].Int() < keys[j].Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		less = func(i, j int) bool { return keys[i].Uint() < keys[j].Uint() }
+	case reflect.String:
+		less = func(i, j int) bool { return keys[i].String() < keys[j].String() }
+	default:
+		return errors.Errorf("encoder: unsupported map key type %s encountered", v.Type().Key())
+	}
+	sort.Slice(keys, less)
+
+	if err := encoder.EncodeUVarint(uint64(len(keys))); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := encoder.encodeValue(key); err != nil {
+			return err
+		}
+		if err := encoder.encodeValue(v.MapIndex(key)); err != nil {
+			return errors.Wrapf(err, "encoder: failed to encode %s value %v", v.Type(), key)
+		}
+	}
+	return nil
+}
+
+// encodeStruct encodes the exported fields in the declaration order
+func (encoder *Encoder) encodeStruct(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get(tagName)
+		if field.PkgPath != "" || tag == "-" {
+			continue
+		}
+
+		var err error
+		switch tag {
+		case "":
+			err = encoder.encodeValue(v.Field(i))
+		case "flat_set":
+			err = encoder.encodeSet(v.Field(i), nil)
+		default:
+			err = errors.Errorf("encoder: unknown tag %q", tag)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "encoder: failed to encode %s.%s", t.Name(), field.Name)
+		}
+	}
+	return nil
+}
+
+// asMarshaller returns the TransactionMarshaller implemented by the value or by its address.
+// Pointers are optionals, so the marshaller of the pointed value is used once the optional flag is written.
+func asMarshaller(v reflect.Value) (TransactionMarshaller, bool) {
+	if v.Kind() == reflect.Ptr {
+		return nil, false
+	}
+	if v.Type().Implements(marshallerType) && v.CanInterface() {
+		return v.Interface().(TransactionMarshaller), true
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(marshallerType) && v.Addr().CanInterface() {
+		return v.Addr().Interface().(TransactionMarshaller), true
+	}
+	return nil, false
+}
@@ -57,6 +57,48 @@ func (encoder *RollingEncoder) EncodeLittleEndianUInt32(i uint32) {
 	}
 }
 
+func (encoder *RollingEncoder) EncodeOptional(v interface{}) {
+	if encoder.err == nil {
+		encoder.err = encoder.next.EncodeOptional(v)
+	}
+}
+
+func (encoder *RollingEncoder) EncodeBytes(b []byte) {
+	if encoder.err == nil {
+		encoder.err = encoder.next.EncodeBytes(b)
+	}
+}
+
+func (encoder *RollingEncoder) EncodeFixedBytes(b []byte, n int) {
+	if encoder.err == nil {
+		encoder.err = encoder.next.EncodeFixedBytes(b, n)
+	}
+}
+
+func (encoder *RollingEncoder) EncodeStaticVariant(tag uint64, v interface{}) {
+	if encoder.err == nil {
+		encoder.err = encoder.next.EncodeStaticVariant(tag, v)
+	}
+}
+
+func (encoder *RollingEncoder) EncodeFlatSet(v interface{}, less func(i, j int) bool) {
+	if encoder.err == nil {
+		encoder.err = encoder.next.EncodeFlatSet(v, less)
+	}
+}
+
+func (encoder *RollingEncoder) EncodeFlatMap(v interface{}) {
+	if encoder.err == nil {
+		encoder.err = encoder.next.EncodeFlatMap(v)
+	}
+}
+
+func (encoder *RollingEncoder) EncodeExtension(v interface{}) {
+	if encoder.err == nil {
+		encoder.err = encoder.next.EncodeExtension(v)
+	}
+}
+
 func (encoder *RollingEncoder) Err() error {
 	return encoder.err
 }
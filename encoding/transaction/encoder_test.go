@@ -2,6 +2,8 @@ package transaction
 
 import (
 	"bytes"
+	"encoding/hex"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -34,3 +36,71 @@ func TestEncoder_EncodeMoney_ValueOverflow(t *testing.T) {
 	err := encoder.EncodeMoney("11111111111111111111111111111111111111 SCR")
 	require.Error(t, err)
 }
+
+type testMarshaller struct {
+	value uint16
+}
+
+func (m *testMarshaller) MarshalTransaction(encoder *Encoder) error {
+	return encoder.EncodeUVarint(uint64(m.value))
+}
+
+type testStruct struct {
+	Number   uint32
+	Signed   int16
+	Flag     bool
+	Name     string
+	Data     []byte
+	Fixed    [2]byte
+	Optional *uint8
+	Missing  *string
+	Vector   []uint16
+	Map      map[string]uint8
+	Nested   testMarshaller
+	Skipped  uint64 `transaction:"-"`
+	internal uint64
+}
+
+func TestEncoder_Encode_Struct(t *testing.T) {
+	optional := uint8(7)
+	v := testStruct{
+		Number:   1,
+		Signed:   -2,
+		Flag:     true,
+		Name:     "ab",
+		Data:     []byte{0xff},
+		Fixed:    [2]byte{1, 2},
+		Optional: &optional,
+		Vector:   []uint16{3, 4},
+		Map:      map[string]uint8{"b": 2, "a": 1},
+		Nested:   testMarshaller{300},
+		Skipped:  5,
+		internal: 6,
+	}
+
+	expected := "01000000" + // Number
+		"feff" + // Signed
+		"01" + // Flag
+		"026162" + // Name
+		"01ff" + // Data
+		"0102" + // Fixed
+		"0107" + // Optional
+		"00" + // Missing
+		"0203000400" + // Vector
+		"02016101016202" + // Map, sorted by the keys
+		"ac02" // Nested
+
+	for _, value := range []interface{}{v, &v} {
+		var b bytes.Buffer
+		require.NoError(t, NewEncoder(&b).Encode(value))
+		require.Equal(t, expected, hex.EncodeToString(b.Bytes()))
+	}
+}
+
+func TestEncoder_Encode_Unsupported(t *testing.T) {
+	var b bytes.Buffer
+	require.Error(t, NewEncoder(&b).Encode(1.5))
+	require.Error(t, NewEncoder(&b).Encode(nil))
+	require.Error(t, NewEncoder(&b).Encode([]json.RawMessage{json.RawMessage("{}")}))
+	require.Error(t, NewEncoder(&b).Encode(map[bool]uint8{true: 1}))
+}
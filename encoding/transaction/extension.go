@@ -0,0 +1,96 @@
+package transaction
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// EncodeExtension encodes graphene::protocol::extension<T>. v has to be a struct, or a pointer to one,
+// of optional fields: pointers, slices or maps, an empty slice or map is absent. The number of the present
+// fields is written first, then every present field as its index in the struct followed by its value.
+func (encoder *Encoder) EncodeExtension(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return errors.Errorf("encoder: extension expects a struct, got %T", v)
+	}
+
+	var present []int
+	for i := 0; i < rv.NumField(); i++ {
+		if err := checkExtensionField(rv.Type().Field(i)); err != nil {
+			return err
+		}
+		if field := rv.Field(i); !field.IsNil() && (field.Kind() == reflect.Ptr || field.Len() > 0) {
+			present = append(present, i)
+		}
+	}
+
+	if err := encoder.EncodeUVarint(uint64(len(present))); err != nil {
+		return err
+	}
+	for _, i := range present {
+		if err := encoder.EncodeUVarint(uint64(i)); err != nil {
+			return err
+		}
+		if err := encoder.encodeReflect(rv.Field(i)); err != nil {
+			return errors.Wrapf(err, "encoder: failed to encode %s.%s", rv.Type().Name(), rv.Type().Field(i).Name)
+		}
+	}
+	return nil
+}
+
+// DecodeExtension decodes graphene::protocol::extension<T> into v, a pointer to a struct of optional fields,
+// see EncodeExtension
+func (decoder *Decoder) DecodeExtension(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("decoder: extension expects a pointer to a struct, got %T", v)
+	}
+	rv = rv.Elem()
+
+	n, err := decoder.DecodeLength()
+	if err != nil {
+		return err
+	}
+	if n > rv.NumField() {
+		return errors.Errorf("decoder: %d fields of %s with %d fields", n, rv.Type().Name(), rv.NumField())
+	}
+
+	last := -1
+	for k := 0; k < n; k++ {
+		index, err := decoder.DecodeUVarint()
+		if err != nil {
+			return err
+		}
+		if int(index) <= last || int(index) >= rv.NumField() {
+			return errors.Errorf("decoder: unexpected %s field %d", rv.Type().Name(), index)
+		}
+		last = int(index)
+
+		field := rv.Type().Field(last)
+		if err := checkExtensionField(field); err != nil {
+			return err
+		}
+
+		value := rv.Field(last)
+		if value.Kind() == reflect.Ptr {
+			value.Set(reflect.New(field.Type.Elem()))
+			err = decoder.Decode(value.Interface())
+		} else {
+			err = decoder.Decode(value.Addr().Interface())
+		}
+		if err != nil {
+			return errors.Wrapf(err, "decoder: failed to decode %s.%s", rv.Type().Name(), field.Name)
+		}
+	}
+	return nil
+}
+
+func checkExtensionField(field reflect.StructField) error {
+	switch field.Type.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return nil
+	default:
+		return errors.Errorf("extension field %s has to be optional, got %s", field.Name, field.Type)
+	}
+}
@@ -0,0 +1,89 @@
+// Package keys derives the private keys the way the BitShares wallets do:
+// from an account name and a password, or from a brain key.
+package keys
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/scorum/bitshares-go/types"
+)
+
+// Role is the authority a key derived from a password is used for
+type Role string
+
+const (
+	Owner  Role = "owner"
+	Active Role = "active"
+	Memo   Role = "memo"
+)
+
+// Roles are the roles of the keys created by the web wallet for an account
+var Roles = []Role{Owner, Active, Memo}
+
+// KeyPair is a derived private key and its public key
+type KeyPair struct {
+	PrivateKey *btcec.PrivateKey
+	PublicKey  types.PublicKey
+}
+
+// NewKeyPair returns the key pair of the private key, the public key has the BitShares prefix
+func NewKeyPair(privateKey *btcec.PrivateKey) *KeyPair {
+	return &KeyPair{
+		PrivateKey: privateKey,
+		PublicKey:  types.NewPublicKey(privateKey.PubKey(), types.DefaultAddressPrefix),
+	}
+}
+
+// FromSeed derives the private key from SHA-256 of the seed
+func FromSeed(seed string) *KeyPair {
+	digest := sha256.Sum256([]byte(seed))
+	privateKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), digest[:])
+	return NewKeyPair(privateKey)
+}
+
+// FromPassword derives the key of the account role from the password, the seed is account + role + password
+func FromPassword(account string, role Role, password string) *KeyPair {
+	return FromSeed(account + string(role) + password)
+}
+
+// FromPasswordRoles derives the keys of all the roles, see FromPassword
+func FromPasswordRoles(account string, password string) map[Role]*KeyPair {
+	keys := make(map[Role]*KeyPair, len(Roles))
+	for _, role := range Roles {
+		keys[role] = FromPassword(account, role, password)
+	}
+	return keys
+}
+
+// FromBrainKey derives the key with the sequence number from the brain key:
+// SHA-256 of SHA-512 of the normalized brain key, a space and the decimal sequence number
+func FromBrainKey(brainKey string, sequence uint32) *KeyPair {
+	digest := sha512.Sum512([]byte(NormalizeBrainKey(brainKey) + " " + strconv.FormatUint(uint64(sequence), 10)))
+	privateKey := sha256.Sum256(digest[:])
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), privateKey[:])
+	return NewKeyPair(key)
+}
+
+// NormalizeBrainKey uppercases the brain key and collapses the whitespaces to a single space
+// like the CLI wallet does
+func NormalizeBrainKey(brainKey string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(brainKey)), " ")
+}
+
+// WIF returns the private key in the wallet import format, uncompressed like the BitShares wallets export it
+func (kp *KeyPair) WIF() string {
+	wif, _ := btcutil.NewWIF(kp.PrivateKey, &chaincfg.MainNetParams, false)
+	return wif.String()
+}
+
+// WithPrefix returns the key pair with the chain prefix of the public key,
+// e.g. database.Config.GrapheneAddressPrefix
+func (kp *KeyPair) WithPrefix(prefix string) *KeyPair {
+	return &KeyPair{PrivateKey: kp.PrivateKey, PublicKey: kp.PublicKey.WithPrefix(prefix)}
+}
@@ -0,0 +1,60 @@
+package keys
+
+import (
+	"testing"
+
+	"github.com/scorum/bitshares-go/encoding/wif"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromSeed(t *testing.T) {
+	// the well known key of the Graphene genesis is derived from "nathan"
+	key := FromSeed("nathan")
+	require.Equal(t, "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3", key.WIF())
+	require.Equal(t, "BTS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV", key.PublicKey.String())
+	require.Equal(t, "TEST6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV", key.WithPrefix("TEST").PublicKey.String())
+
+	// the WIF can be used to sign
+	publicKey, err := wif.GetPublicKey(key.WIF())
+	require.NoError(t, err)
+	require.Equal(t, key.PublicKey.Bytes(), publicKey)
+}
+
+func TestFromPassword(t *testing.T) {
+	// the vectors of python-graphenelib (PasswordKey), the algorithm of the web wallet,
+	// the role is arbitrary and the keys are of Steem there
+	for _, vector := range []struct {
+		password, publicKey string
+	}{
+		{"Aang7foN3oz1Ungai2qua5toh3map8ladei1eem2ohsh2shuo8aeji9Thoseo7ah", "STM5NyCrrXHmdikC6QPRAPoDjSHVQJe3WC5bMZuF6YhqhSsfYfjhN"},
+		{"iep1Mees9eghiifahwei5iidi0Sazae9aigaeT7itho3quoo2dah5zuvobaelau5", "STM8gyvJtYyv5ZbT2ZxbAtgufQ5ovV2bq6EQp4YDTzQuSwyg7Ckry"},
+	} {
+		key := FromPassword("xeroc", Role("posting"), vector.password).WithPrefix("STM")
+		require.Equal(t, vector.publicKey, key.PublicKey.String())
+	}
+
+	keys := FromPasswordRoles("cali4889", "P5KDhhiyCFuE4Wu2bDdDeTWsyRJZxzWv9eaWq6dDtR4RE")
+	require.Len(t, keys, 3)
+
+	for role, expected := range map[Role][2]string{
+		Owner:  {"5KDHznA98npDSbzuY84FyjRvHfiR5fbdWwkyXkAZc2MbHHYH1Gy", "BTS8AZSrSa16HzhdHKwGpKB5fYYrtTnKaiAjK9JxgYiB3aGVWskK5"},
+		Active: {"5K1b1Sv6RLLxxw29ZB4ajf6xvrYXKWmhkGohTvfD8X6PkoofPFb", "BTS8gsUT9B1UXwL6BN81VLZUPTt616WsBGsr8Uh5mQH3DrfErgGSK"},
+		Memo:   {"5KTCBJQ3wJNBSexEeWb8acy4gNqPpD1HcNn5sZQCgJBediwxM5E", "BTS87B2w4HKx7DRMSYSdbSLTZJZPQfieehksuR2XZXDuwAhbgxgaZ"},
+	} {
+		require.Equal(t, expected[0], keys[role].WIF(), role)
+		require.Equal(t, expected[1], keys[role].PublicKey.String(), role)
+	}
+}
+
+func TestFromBrainKey(t *testing.T) {
+	brainKey := "  sedile  RAPHE\tvaluta MUTELY tulwar "
+	require.Equal(t, "SEDILE RAPHE VALUTA MUTELY TULWAR", NormalizeBrainKey(brainKey))
+	require.Equal(t, FromBrainKey("SEDILE RAPHE VALUTA MUTELY TULWAR", 0).WIF(), FromBrainKey(brainKey, 0).WIF())
+
+	// the vectors of python-graphenelib (BrainKey), the algorithm of derive_private_key of the CLI wallet
+	brainKey = "COLORER BICORN KASBEKE FAERIE LOCHIA GOMUTI SOVKHOZ Y GERMAL AUNTIE PERFUMY TIME FEATURE GANGAN CELEMIN MATZO"
+	require.Equal(t, "5Hsbn6kXio4bb7eW5bX7kTp2sdkmbzP8kGWoau46Cf7en7T1RRE", FromBrainKey(brainKey, 1).WIF())
+	require.Equal(t, "5K9MHEyiSye5iFL2srZu3ZVjzAZjcQxUgUvuttcVrymovFbU4cc", FromBrainKey(brainKey, 2).WIF())
+	require.Equal(t, "BTS6Lduu3V4hoDBeasWrdG45tAGeKG4b7XZFqF4GiryqTNZmWDdW7", FromBrainKey(brainKey, 1).PublicKey.String())
+	require.Equal(t, "BTS8gxRjNrdQHV2bsGDSWdkaANjYybqC2xJrqbAz8TjutzK7WTiGA", FromBrainKey(brainKey, 2).PublicKey.String())
+}
@@ -0,0 +1,62 @@
+package bitshares
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/caller"
+)
+
+// lazyAPI is a caller resolving the API ID on the first call.
+// The API is created with a zero ID which is replaced by the resolved one.
+type lazyAPI struct {
+	name    string
+	next    caller.Caller
+	resolve func(ctx context.Context) (caller.APIID, error)
+
+	mutex    sync.Mutex
+	id       caller.APIID
+	resolved bool
+}
+
+func newLazyAPI(name string, next caller.Caller, resolve func(ctx context.Context) (caller.APIID, error)) *lazyAPI {
+	return &lazyAPI{name: name, next: next, resolve: resolve}
+}
+
+// apiID resolves the API ID once, a failed attempt is retried on the next call
+func (api *lazyAPI) apiID(ctx context.Context) (caller.APIID, error) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if !api.resolved {
+		id, err := api.resolve(ctx)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to get %s API ID", api.name)
+		}
+		api.id = id
+		api.resolved = true
+	}
+	return api.id, nil
+}
+
+func (api *lazyAPI) Call(_ caller.APIID, method string, args []interface{}, reply interface{}) error {
+	return api.CallContext(context.Background(), 0, method, args, reply)
+}
+
+func (api *lazyAPI) CallContext(ctx context.Context, _ caller.APIID, method string, args []interface{}, reply interface{}) error {
+	id, err := api.apiID(ctx)
+	if err != nil {
+		return err
+	}
+	return caller.CallContext(ctx, api.next, id, method, args, reply)
+}
+
+func (api *lazyAPI) SetCallback(_ caller.APIID, method string, callback func(raw json.RawMessage)) error {
+	id, err := api.apiID(context.Background())
+	if err != nil {
+		return err
+	}
+	return api.next.SetCallback(id, method, callback)
+}
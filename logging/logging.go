@@ -0,0 +1,107 @@
+// Package logging defines the leveled logger used by the library.
+// The library is silent by default, a Logger is injected through the client and transport options,
+// so the logs can be routed to any structured logging backend:
+//
+//	client, err := bitshares.NewClient(url, bitshares.WithLogger(logging.NewStd(log.New(os.Stderr, "", log.LstdFlags), logging.LevelInfo)))
+package logging
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Logger is a leveled structured logger.
+// The keyvals are alternating keys and values, e.g. Warn("node failed", "node", 1, "error", err).
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// Level is the severity of a message
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// Nop is a Logger discarding every message
+var Nop Logger = nop{}
+
+type nop struct{}
+
+func (nop) Debug(string, ...interface{}) {}
+func (nop) Info(string, ...interface{})  {}
+func (nop) Warn(string, ...interface{})  {}
+func (nop) Error(string, ...interface{}) {}
+
+// Std is a Logger writing to a standard library logger, e.g.
+//
+//	[WARN] connection is lost url=wss://node error="EOF"
+type Std struct {
+	logger *log.Logger
+	min    Level
+}
+
+// NewStd creates a Logger writing the messages of the given level and above to the logger
+func NewStd(logger *log.Logger, min Level) *Std {
+	return &Std{logger: logger, min: min}
+}
+
+func (s *Std) Debug(msg string, keyvals ...interface{}) { s.log(LevelDebug, msg, keyvals) }
+func (s *Std) Info(msg string, keyvals ...interface{})  { s.log(LevelInfo, msg, keyvals) }
+func (s *Std) Warn(msg string, keyvals ...interface{})  { s.log(LevelWarn, msg, keyvals) }
+func (s *Std) Error(msg string, keyvals ...interface{}) { s.log(LevelError, msg, keyvals) }
+
+func (s *Std) log(level Level, msg string, keyvals []interface{}) {
+	if level < s.min {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s", level, msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		var value interface{} = "(missing)"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		fmt.Fprintf(&b, " %v=%s", keyvals[i], format(value))
+	}
+	s.logger.Output(3, b.String())
+}
+
+// format quotes the values containing spaces and the errors
+func format(value interface{}) string {
+	var s string
+	switch v := value.(type) {
+	case error:
+		return fmt.Sprintf("%q", v.Error())
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		s = fmt.Sprint(v)
+	}
+	if strings.ContainsAny(s, " \t\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
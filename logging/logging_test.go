@@ -0,0 +1,25 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStd(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStd(log.New(&buf, "", 0), LevelInfo)
+
+	logger.Debug("hidden")
+	logger.Info("connected", "url", "ws://node")
+	logger.Warn("connection is lost", "url", "ws://node", "error", errors.New("unexpected EOF"))
+	logger.Error("odd", "key")
+
+	require.Equal(t, `[INFO] connected url=ws://node
+[WARN] connection is lost url=ws://node error="unexpected EOF"
+[ERROR] odd key=(missing)
+`, buf.String())
+}
@@ -0,0 +1,38 @@
+// Package metrics collects the RPC layer metrics: the calls, their latency and errors
+// labeled by API and method, the pending calls, the reconnects and the notices.
+// The transports report to a Collector given with their WithMetrics option,
+// Prometheus is a Collector serving them in the Prometheus text exposition format:
+//
+//	collector := metrics.NewPrometheus()
+//	http.Handle("/metrics", collector)
+//
+//	transport, err := websocket.NewTransport(url, websocket.WithMetrics(collector))
+package metrics
+
+import "time"
+
+// Collector receives the metrics from the transports.
+// The API is the API name, e.g. "database", or the API ID if the name is not known.
+type Collector interface {
+	// CallStarted is called once the call is sent
+	CallStarted(api, method string)
+
+	// CallFinished is called once the call is completed, err is nil on success
+	CallFinished(api, method string, duration time.Duration, err error)
+
+	// Reconnected is called once the connection to the node is restored
+	Reconnected()
+
+	// NoticeReceived is called for every notice delivered to the callback registered with the method
+	NoticeReceived(method string)
+}
+
+// Nop is a Collector discarding the metrics
+var Nop Collector = nop{}
+
+type nop struct{}
+
+func (nop) CallStarted(string, string)                        {}
+func (nop) CallFinished(string, string, time.Duration, error) {}
+func (nop) Reconnected()                                      {}
+func (nop) NoticeReceived(string)                             {}
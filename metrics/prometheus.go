@@ -0,0 +1,215 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds of the latency histogram buckets in seconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Prometheus is a Collector serving the metrics in the Prometheus text exposition format:
+//
+//	bitshares_rpc_requests_total{api,method}           counter
+//	bitshares_rpc_errors_total{api,method}             counter
+//	bitshares_rpc_request_duration_seconds{api,method} histogram
+//	bitshares_rpc_pending_calls                        gauge
+//	bitshares_rpc_reconnects_total                     counter
+//	bitshares_rpc_notices_total{method}                counter
+type Prometheus struct {
+	namespace string
+	buckets   []float64
+
+	mutex      sync.Mutex
+	calls      map[callKey]*callStats
+	pending    int64
+	reconnects uint64
+	notices    map[string]uint64
+}
+
+type callKey struct {
+	api    string
+	method string
+}
+
+type callStats struct {
+	requests uint64
+	errors   uint64
+	buckets  []uint64 // not cumulative, one per bucket
+	sum      float64
+}
+
+// PrometheusOption configures the Prometheus collector
+type PrometheusOption func(*Prometheus)
+
+// WithNamespace sets the prefix of the metric names, "bitshares" by default
+func WithNamespace(namespace string) PrometheusOption {
+	return func(p *Prometheus) {
+		p.namespace = namespace
+	}
+}
+
+// WithBuckets sets the upper bounds of the latency histogram buckets in seconds, DefaultBuckets by default
+func WithBuckets(buckets ...float64) PrometheusOption {
+	return func(p *Prometheus) {
+		p.buckets = append([]float64{}, buckets...)
+		sort.Float64s(p.buckets)
+	}
+}
+
+// NewPrometheus creates a Prometheus collector
+func NewPrometheus(options ...PrometheusOption) *Prometheus {
+	p := &Prometheus{
+		namespace: "bitshares",
+		buckets:   DefaultBuckets,
+		calls:     make(map[callKey]*callStats),
+		notices:   make(map[string]uint64),
+	}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+func (p *Prometheus) CallStarted(api, method string) {
+	p.mutex.Lock()
+	p.pending++
+	p.mutex.Unlock()
+}
+
+func (p *Prometheus) CallFinished(api, method string, duration time.Duration, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.pending--
+
+	key := callKey{api: api, method: method}
+	stats, ok := p.calls[key]
+	if !ok {
+		stats = &callStats{buckets: make([]uint64, len(p.buckets))}
+		p.calls[key] = stats
+	}
+
+	stats.requests++
+	if err != nil {
+		stats.errors++
+	}
+
+	seconds := duration.Seconds()
+	stats.sum += seconds
+	for i, bound := range p.buckets {
+		if seconds <= bound {
+			stats.buckets[i]++
+			break
+		}
+	}
+}
+
+func (p *Prometheus) Reconnected() {
+	p.mutex.Lock()
+	p.reconnects++
+	p.mutex.Unlock()
+}
+
+func (p *Prometheus) NoticeReceived(method string) {
+	p.mutex.Lock()
+	p.notices[method]++
+	p.mutex.Unlock()
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	buf := bufio.NewWriter(w)
+	p.write(buf)
+	buf.Flush()
+}
+
+func (p *Prometheus) write(w *bufio.Writer) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	keys := make([]callKey, 0, len(p.calls))
+	for key := range p.calls {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].api != keys[j].api {
+			return keys[i].api < keys[j].api
+		}
+		return keys[i].method < keys[j].method
+	})
+
+	name := p.namespace + "_rpc_requests_total"
+	header(w, name, "counter", "Number of RPC calls.")
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s%s %d\n", name, labels("api", key.api, "method", key.method), p.calls[key].requests)
+	}
+
+	name = p.namespace + "_rpc_errors_total"
+	header(w, name, "counter", "Number of failed RPC calls.")
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s%s %d\n", name, labels("api", key.api, "method", key.method), p.calls[key].errors)
+	}
+
+	name = p.namespace + "_rpc_request_duration_seconds"
+	header(w, name, "histogram", "RPC call latency in seconds.")
+	for _, key := range keys {
+		stats := p.calls[key]
+		var cumulative uint64
+		for i, bound := range p.buckets {
+			cumulative += stats.buckets[i]
+			le := strconv.FormatFloat(bound, 'g', -1, 64)
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels("api", key.api, "method", key.method, "le", le), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels("api", key.api, "method", key.method, "le", "+Inf"), stats.requests)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, labels("api", key.api, "method", key.method), strconv.FormatFloat(stats.sum, 'g', -1, 64))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, labels("api", key.api, "method", key.method), stats.requests)
+	}
+
+	name = p.namespace + "_rpc_pending_calls"
+	header(w, name, "gauge", "Number of RPC calls awaiting a response.")
+	fmt.Fprintf(w, "%s %d\n", name, p.pending)
+
+	name = p.namespace + "_rpc_reconnects_total"
+	header(w, name, "counter", "Number of restored connections.")
+	fmt.Fprintf(w, "%s %d\n", name, p.reconnects)
+
+	methods := make([]string, 0, len(p.notices))
+	for method := range p.notices {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	name = p.namespace + "_rpc_notices_total"
+	header(w, name, "counter", "Number of notices delivered to the callbacks.")
+	for _, method := range methods {
+		fmt.Fprintf(w, "%s%s %d\n", name, labels("method", method), p.notices[method])
+	}
+}
+
+func header(w *bufio.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels formats the label pairs, e.g. {api="database",method="get_objects"}
+func labels(pairs ...string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, pairs[i], labelEscaper.Replace(pairs[i+1]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheus(t *testing.T) {
+	p := NewPrometheus(WithBuckets(0.1, 1))
+
+	p.CallStarted("database", "get_objects")
+	p.CallFinished("database", "get_objects", 50*time.Millisecond, nil)
+	p.CallStarted("database", "get_objects")
+	p.CallFinished("database", "get_objects", 500*time.Millisecond, errors.New("connection reset"))
+	p.CallStarted("2", `get_"quoted"`)
+	p.Reconnected()
+	p.NoticeReceived("set_block_applied_callback")
+
+	recorder := httptest.NewRecorder()
+	p.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+	require.Equal(t, `# HELP bitshares_rpc_requests_total Number of RPC calls.
+# TYPE bitshares_rpc_requests_total counter
+bitshares_rpc_requests_total{api="database",method="get_objects"} 2
+# HELP bitshares_rpc_errors_total Number of failed RPC calls.
+# TYPE bitshares_rpc_errors_total counter
+bitshares_rpc_errors_total{api="database",method="get_objects"} 1
+# HELP bitshares_rpc_request_duration_seconds RPC call latency in seconds.
+# TYPE bitshares_rpc_request_duration_seconds histogram
+bitshares_rpc_request_duration_seconds_bucket{api="database",method="get_objects",le="0.1"} 1
+bitshares_rpc_request_duration_seconds_bucket{api="database",method="get_objects",le="1"} 2
+bitshares_rpc_request_duration_seconds_bucket{api="database",method="get_objects",le="+Inf"} 2
+bitshares_rpc_request_duration_seconds_sum{api="database",method="get_objects"} 0.55
+bitshares_rpc_request_duration_seconds_count{api="database",method="get_objects"} 2
+# HELP bitshares_rpc_pending_calls Number of RPC calls awaiting a response.
+# TYPE bitshares_rpc_pending_calls gauge
+bitshares_rpc_pending_calls 1
+# HELP bitshares_rpc_reconnects_total Number of restored connections.
+# TYPE bitshares_rpc_reconnects_total counter
+bitshares_rpc_reconnects_total 1
+# HELP bitshares_rpc_notices_total Number of notices delivered to the callbacks.
+# TYPE bitshares_rpc_notices_total counter
+bitshares_rpc_notices_total{method="set_block_applied_callback"} 1
+`, recorder.Body.String())
+
+	require.Equal(t, `{method="get_\"quoted\""}`, labels("method", `get_"quoted"`))
+}
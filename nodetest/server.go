@@ -0,0 +1,289 @@
+// Package nodetest provides an in-process BitShares node emulation for tests.
+// It speaks the same call/notice websocket protocol as a real node, so the
+// library can be tested end to end without network access:
+//
+//	server := nodetest.NewServer()
+//	defer server.Close()
+//
+//	server.Respond("database", "get_chain_id", "4018d784...")
+//	client, err := bitshares.NewClient(server.URL)
+package nodetest
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/transport"
+	"golang.org/x/net/websocket"
+)
+
+// LoginAPI is the name of the login_api, it always has ID 1
+const LoginAPI = "login"
+
+// APIs lists the APIs available on the server, their IDs are the indexes in the list
+var APIs = []string{"", LoginAPI, "database", "history", "network_broadcast"}
+
+// Handler handles a call, the result is marshalled to JSON.
+// A returned *transport.RPCError is sent as is, other errors are wrapped into one.
+type Handler func(params []json.RawMessage) (interface{}, error)
+
+// Call is a call received by the server
+type Call struct {
+	API    string
+	Method string
+	Params []json.RawMessage
+}
+
+// Server is a fake BitShares node
+type Server struct {
+	// URL of the server, ws://127.0.0.1:port
+	URL string
+
+	server *httptest.Server
+
+	mutex     sync.Mutex
+	handlers  map[string]Handler
+	calls     []Call
+	callbacks []*callback
+	conns     map[*websocket.Conn]*sync.Mutex
+}
+
+// callback is registered by a *_callback method
+type callback struct {
+	conn   *websocket.Conn
+	method string
+	id     json.RawMessage
+}
+
+// NewServer starts a new server. The login_api is served out of the box,
+// any other method has to be scripted with Handle or Respond.
+func NewServer() *Server {
+	s := &Server{
+		handlers: make(map[string]Handler),
+		conns:    make(map[*websocket.Conn]*sync.Mutex),
+	}
+
+	s.Respond(LoginAPI, "login", true)
+	for id, name := range APIs {
+		if id > 1 {
+			s.Respond(LoginAPI, name, id)
+		}
+	}
+	s.Handle(LoginAPI, "get_api_by_name", func(params []json.RawMessage) (interface{}, error) {
+		var name string
+		if err := Unmarshal(params, &name); err != nil {
+			return nil, err
+		}
+		for id, api := range APIs {
+			if api == name && id > 0 {
+				return id, nil
+			}
+		}
+		return nil, nil
+	})
+
+	s.server = httptest.NewServer(websocket.Handler(s.serve))
+	s.URL = "ws" + strings.TrimPrefix(s.server.URL, "http")
+	return s
+}
+
+// Close drops all of the connections and shuts down the server
+func (s *Server) Close() {
+	s.Drop()
+	s.server.Close()
+}
+
+// Handle scripts the method of the API with the given name
+func (s *Server) Handle(api, method string, handler Handler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.handlers[api+"."+method] = handler
+}
+
+// Respond scripts the method to always return the given result
+func (s *Server) Respond(api, method string, result interface{}) {
+	s.Handle(api, method, func([]json.RawMessage) (interface{}, error) {
+		return result, nil
+	})
+}
+
+// RespondJSON scripts the method to always return the given raw JSON result
+func (s *Server) RespondJSON(api, method string, result string) {
+	s.Respond(api, method, json.RawMessage(result))
+}
+
+// RespondError scripts the method to always fail with the given message
+func (s *Server) RespondError(api, method string, message string) {
+	s.Handle(api, method, func([]json.RawMessage) (interface{}, error) {
+		return nil, &transport.RPCError{Code: 1, Message: message}
+	})
+}
+
+// Calls returns the calls received so far
+func (s *Server) Calls() []Call {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Call{}, s.calls...)
+}
+
+// Notify sends a notice to every callback registered with the given method,
+// e.g. Notify("set_block_applied_callback", []string{blockID})
+func (s *Server) Notify(method string, result interface{}) error {
+	s.mutex.Lock()
+	var callbacks []*callback
+	for _, c := range s.callbacks {
+		if c.method == method {
+			callbacks = append(callbacks, c)
+		}
+	}
+	s.mutex.Unlock()
+
+	for _, c := range callbacks {
+		notice := map[string]interface{}{
+			"method": "notice",
+			"params": []interface{}{c.id, result},
+		}
+		if err := s.send(c.conn, notice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Drop closes all of the client connections
+func (s *Server) Drop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.callbacks = nil
+}
+
+func (s *Server) serve(conn *websocket.Conn) {
+	s.mutex.Lock()
+	s.conns[conn] = &sync.Mutex{}
+	s.mutex.Unlock()
+
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+	}()
+
+	for {
+		var request struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := websocket.JSON.Receive(conn, &request); err != nil {
+			return
+		}
+
+		response := map[string]interface{}{"id": request.ID}
+		result, err := s.call(conn, request.Method, request.Params)
+		if err != nil {
+			rpcErr, ok := err.(*transport.RPCError)
+			if !ok {
+				rpcErr = &transport.RPCError{Code: 1, Message: err.Error()}
+			}
+			response["error"] = rpcErr
+		} else {
+			response["result"] = result
+		}
+
+		if err := s.send(conn, response); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) call(conn *websocket.Conn, method string, params []json.RawMessage) (interface{}, error) {
+	if method != "call" || len(params) != 3 {
+		return nil, errors.Errorf("invalid request %s %s", method, params)
+	}
+
+	api, err := apiName(params[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var name string
+	if err := json.Unmarshal(params[1], &name); err != nil {
+		return nil, errors.Wrap(err, "invalid method")
+	}
+
+	var args []json.RawMessage
+	if err := json.Unmarshal(params[2], &args); err != nil {
+		return nil, errors.Wrap(err, "invalid params")
+	}
+
+	s.mutex.Lock()
+	s.calls = append(s.calls, Call{API: api, Method: name, Params: args})
+	handler, ok := s.handlers[api+"."+name]
+
+	// register the callback, the callback ID is the first param
+	if strings.HasSuffix(name, "_callback") && len(args) > 0 {
+		s.callbacks = append(s.callbacks, &callback{conn: conn, method: name, id: args[0]})
+	}
+	s.mutex.Unlock()
+
+	if !ok {
+		if strings.HasSuffix(name, "_callback") {
+			return nil, nil
+		}
+		return nil, errors.Errorf("method %s.%s is not scripted", api, name)
+	}
+	return handler(args)
+}
+
+func (s *Server) send(conn *websocket.Conn, v interface{}) error {
+	s.mutex.Lock()
+	wmutex, ok := s.conns[conn]
+	s.mutex.Unlock()
+	if !ok {
+		return errors.New("connection is closed")
+	}
+
+	wmutex.Lock()
+	defer wmutex.Unlock()
+	return websocket.JSON.Send(conn, v)
+}
+
+// apiName resolves the API addressed either by ID or by name
+func apiName(raw json.RawMessage) (string, error) {
+	var id int
+	if err := json.Unmarshal(raw, &id); err == nil {
+		if id <= 0 || id >= len(APIs) {
+			return "", errors.Errorf("unknown API %d", id)
+		}
+		return APIs[id], nil
+	}
+
+	var name string
+	if err := json.Unmarshal(raw, &name); err != nil {
+		return "", errors.Errorf("invalid API %s", raw)
+	}
+	return name, nil
+}
+
+// Unmarshal decodes the call params into the given values, e.g.
+//
+//	var blockNum uint32
+//	err := nodetest.Unmarshal(params, &blockNum)
+func Unmarshal(params []json.RawMessage, values ...interface{}) error {
+	if len(params) < len(values) {
+		return errors.Errorf("expected %d params, got %d", len(values), len(params))
+	}
+	for i, v := range values {
+		if err := json.Unmarshal(params[i], v); err != nil {
+			return errors.Wrapf(err, "invalid param #%d", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package nodetest
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scorum/bitshares-go/transport"
+	"github.com/scorum/bitshares-go/transport/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	tr, err := websocket.NewTransport(server.URL)
+	require.NoError(t, err)
+	defer tr.Close()
+
+	t.Run("login API", func(t *testing.T) {
+		var id int
+		require.NoError(t, tr.Call(1, "history", []interface{}{}, &id))
+		require.Equal(t, 3, id)
+	})
+
+	t.Run("scripted", func(t *testing.T) {
+		server.Respond("history", "get_market_history_buckets", []int{15, 60})
+
+		var buckets []int
+		require.NoError(t, tr.Call(3, "get_market_history_buckets", []interface{}{}, &buckets))
+		require.Equal(t, []int{15, 60}, buckets)
+
+		calls := server.Calls()
+		require.Equal(t, Call{API: "history", Method: "get_market_history_buckets", Params: []json.RawMessage{}}, calls[len(calls)-1])
+	})
+
+	t.Run("not scripted", func(t *testing.T) {
+		require.Error(t, tr.Call(2, "get_chain_id", []interface{}{}, nil))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		server.RespondError("database", "get_objects", "Assert Exception")
+		require.EqualError(t, tr.Call(2, "get_objects", []interface{}{}, nil), "1: Assert Exception")
+
+		server.RespondError("database", "get_account_by_name", "Assert Exception: no such account")
+		err := tr.Call(2, "get_account_by_name", []interface{}{"nonexists"}, nil)
+		require.True(t, errors.Is(err, transport.ErrUnknownAccount))
+	})
+
+	t.Run("notice", func(t *testing.T) {
+		notices := make(chan json.RawMessage, 1)
+		require.NoError(t, tr.SetCallback(2, "set_block_applied_callback", func(raw json.RawMessage) {
+			notices <- raw
+		}))
+		require.NoError(t, server.Notify("set_block_applied_callback", []string{"block"}))
+
+		select {
+		case raw := <-notices:
+			require.JSONEq(t, `["block"]`, string(raw))
+		case <-time.After(5 * time.Second):
+			t.Fatal("notice has not been delivered")
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package bitshares
+
+import (
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/logging"
+)
+
+// The names of the APIs which can be resolved eagerly with WithAPIs
+const (
+	DatabaseAPI         = "database"
+	HistoryAPI          = "history"
+	NetworkBroadcastAPI = "network_broadcast"
+)
+
+// Option configures the Client
+type Option func(*Client)
+
+// WithCaller makes the client use the given CallCloser instead of dialing the URL,
+// e.g. a failover pool or a recorded session. The client takes the ownership of it.
+func WithCaller(cc caller.CallCloser) Option {
+	return func(client *Client) {
+		client.cc = cc
+	}
+}
+
+// WithCredentials logs in with the given username and password instead of the anonymous login
+func WithCredentials(username, password string) Option {
+	return func(client *Client) {
+		client.username = username
+		client.password = password
+	}
+}
+
+// WithAPIs sets the APIs resolved on creation, all of them by default.
+// The rest are resolved on the first use, so the client can be created on a node
+// which does not provide them. The database API is always resolved on creation.
+func WithAPIs(apis ...string) Option {
+	return func(client *Client) {
+		client.eager = map[string]bool{DatabaseAPI: true}
+		for _, api := range apis {
+			client.eager[api] = true
+		}
+	}
+}
+
+// WithChainID makes the client fail on creation if the node is on a different chain
+func WithChainID(chainID string) Option {
+	return func(client *Client) {
+		client.expectedChainID = chainID
+	}
+}
+
+// WithLogger sets the logger, the client is silent by default.
+// The logger is passed to the transport dialed by the client. A CallCloser given with WithCaller
+// keeps its own logger, e.g. the one set with pool.WithLogger.
+func WithLogger(logger logging.Logger) Option {
+	return func(client *Client) {
+		client.logger = logger
+	}
+}
+
+// WithInterceptors wraps every call made by the client with the interceptors, the first one is the outermost
+func WithInterceptors(interceptors ...caller.Interceptor) Option {
+	return func(client *Client) {
+		client.interceptors = append(client.interceptors, interceptors...)
+	}
+}
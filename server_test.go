@@ -0,0 +1,102 @@
+package bitshares
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/scorum/bitshares-go/nodetest"
+)
+
+const testChainID = "39f5e2ede1f8bc1a3a54a7914414e3779e33193f1f5693510e73cb7a87617447"
+
+// newServer starts a node emulating the testnet with the accounts and assets used by the tests
+func newServer(t *testing.T) *nodetest.Server {
+	server := nodetest.NewServer()
+	t.Cleanup(server.Close)
+
+	accounts := map[string]string{
+		"cali4889": "1.2.1144",
+		"cali4890": "1.2.1145",
+		"cali4891": "1.2.1146",
+	}
+	assets := map[string]string{
+		"TEST":        `{"id":"1.3.0","symbol":"TEST","precision":5,"issuer":"1.2.3","dynamic_asset_data_id":"2.3.0"}`,
+		"PEG.FAKEUSD": `{"id":"1.3.1","symbol":"PEG.FAKEUSD","precision":4,"issuer":"1.2.20","dynamic_asset_data_id":"2.3.1"}`,
+	}
+
+	server.Respond("database", "get_chain_id", testChainID)
+	server.RespondJSON("database", "get_dynamic_global_properties", `{
+		"id": "2.1.0",
+		"head_block_number": 20131520,
+		"head_block_id": "0133302c0c5b8e2a6d0d1c5d5e6f7a8b9c0d1e2f",
+		"time": "2018-06-06T10:00:00",
+		"current_witness": "1.6.7",
+		"last_irreversible_block_num": 20131500
+	}`)
+	server.RespondJSON("database", "get_block", `{
+		"transaction_merkle_root": "0000000000000000000000000000000000000000",
+		"previous": "013330172a8b9c0d1e2f3a4b5c6d7e8f90a1b2c3",
+		"timestamp": "2018-06-06T09:59:00",
+		"witness": "1.6.7",
+		"extensions": [],
+		"witness_signature": "1f3b9c",
+		"transactions": []
+	}`)
+	server.RespondJSON("database", "get_required_fees", `[{"amount": 2000, "asset_id": "1.3.0"}]`)
+	server.Handle("database", "lookup_accounts", func(params []json.RawMessage) (interface{}, error) {
+		var lowerBound string
+		var limit int
+		if err := nodetest.Unmarshal(params, &lowerBound, &limit); err != nil {
+			return nil, err
+		}
+
+		var names []string
+		for name := range accounts {
+			if name >= lowerBound {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		result := [][]string{}
+		for _, name := range names {
+			if len(result) == limit {
+				break
+			}
+			result = append(result, []string{name, accounts[name]})
+		}
+		return result, nil
+	})
+	server.Handle("database", "lookup_asset_symbols", func(params []json.RawMessage) (interface{}, error) {
+		var symbols []string
+		if err := nodetest.Unmarshal(params, &symbols); err != nil {
+			return nil, err
+		}
+		result := make([]json.RawMessage, len(symbols))
+		for i, s := range symbols {
+			result[i] = json.RawMessage("null")
+			if asset, ok := assets[s]; ok {
+				result[i] = json.RawMessage(asset)
+			}
+		}
+		return result, nil
+	})
+	server.Respond("network_broadcast", "broadcast_transaction", nil)
+	server.Handle("network_broadcast", "broadcast_transaction_synchronous", func(params []json.RawMessage) (interface{}, error) {
+		var trx map[string]interface{}
+		if err := nodetest.Unmarshal(params, &trx); err != nil {
+			return nil, err
+		}
+		trx["operation_results"] = []interface{}{[]interface{}{1, "1.7.1032"}}
+		return map[string]interface{}{
+			"id":        "5f1e1b9d9c0e1b2f3a4b5c6d7e8f90a1b2c3d4e5",
+			"block_num": 20131521,
+			"trx_num":   0,
+			"expired":   false,
+			"trx":       trx,
+		}, nil
+	})
+
+	return server
+}
@@ -0,0 +1,422 @@
+// Package remote implements a sign.Signer calling an out-of-process signing daemon,
+// so the private keys can live in an isolated process, and the server the daemon runs.
+//
+// The protocol is newline delimited JSON over a stream connection, usually a unix socket.
+// Every request gets a response before the next request is read:
+//
+//	{"method": "public_key"}
+//	{"public_key": "BTS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV"}
+//
+//	{"method": "sign_digest", "digest": "<hex encoded SHA-256 digest>"}
+//	{"signature": "<hex encoded 65 bytes compact signature>"}
+//
+// A failed request gets {"error": "<message>"}.
+package remote
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/sign"
+	"github.com/scorum/bitshares-go/types"
+)
+
+// The methods of the protocol
+const (
+	MethodPublicKey  = "public_key"
+	MethodSignDigest = "sign_digest"
+)
+
+// ErrClosed is returned by the calls of a closed Signer,
+// and of a broken Signer created by NewSigner as it can't reconnect
+var ErrClosed = errors.New("remote signer is closed")
+
+// Request is a request to the signing daemon
+type Request struct {
+	Method string `json:"method"`
+	Digest string `json:"digest,omitempty"`
+}
+
+// Response is a response of the signing daemon
+type Response struct {
+	PublicKey string `json:"public_key,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Signer is a sign.Signer calling the signing daemon over a single connection.
+// The calls are serialized, it's safe for concurrent use.
+//
+// The connection is closed when a call fails with an I/O error, e.g. its context expires.
+// The Signer created by Dial reconnects on the next call and makes sure the daemon still has the same key,
+// the one created by NewSigner returns ErrClosed from then on.
+type Signer struct {
+	conn      net.Conn // nil when broken
+	reader    *bufio.Reader
+	dial      func(ctx context.Context) (net.Conn, error)
+	publicKey types.PublicKey
+	prefix    string
+
+	mutex  sync.Mutex
+	closed bool
+}
+
+// Option configures the Signer
+type Option func(*Signer)
+
+// WithPrefix makes the signer reject the public key of the daemon unless it has the chain prefix,
+// e.g. database.Config.GrapheneAddressPrefix. A key with any prefix is accepted by default.
+func WithPrefix(prefix string) Option {
+	return func(signer *Signer) {
+		signer.prefix = prefix
+	}
+}
+
+// Dial connects to the signing daemon, e.g. Dial("unix", "/run/bitshares/signer.sock"),
+// and requests its public key
+func Dial(network, address string, options ...Option) (*Signer, error) {
+	return DialContext(context.Background(), network, address, options...)
+}
+
+// DialContext is Dial with a context
+func DialContext(ctx context.Context, network, address string, options ...Option) (*Signer, error) {
+	dial := func(ctx context.Context) (net.Conn, error) {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, network, address)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to connect to the signer")
+		}
+		return conn, nil
+	}
+
+	conn, err := dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := NewSigner(ctx, conn, options...)
+	if err != nil {
+		return nil, err
+	}
+	signer.dial = dial
+	return signer, nil
+}
+
+// NewSigner requests the public key of the signing daemon over the connection.
+// The signer takes the ownership of the connection.
+func NewSigner(ctx context.Context, conn net.Conn, options ...Option) (*Signer, error) {
+	signer := &Signer{}
+	for _, option := range options {
+		option(signer)
+	}
+
+	publicKey, err := signer.connect(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	signer.publicKey = publicKey
+	return signer, nil
+}
+
+// connect requests the public key of the daemon over the new connection,
+// the connection is closed if it fails
+func (signer *Signer) connect(ctx context.Context, conn net.Conn) (types.PublicKey, error) {
+	signer.conn, signer.reader = conn, bufio.NewReader(conn)
+
+	response, err := signer.roundTripContext(ctx, Request{Method: MethodPublicKey})
+	if err != nil {
+		return types.PublicKey{}, err
+	}
+
+	var publicKey types.PublicKey
+	if signer.prefix != "" {
+		publicKey, err = types.ParsePublicKeyWithPrefix(response.PublicKey, signer.prefix)
+	} else {
+		publicKey, err = types.ParsePublicKey(response.PublicKey)
+	}
+	if err != nil {
+		signer.disconnect()
+		return types.PublicKey{}, errors.Wrap(err, "invalid public key of the signer")
+	}
+	return publicKey, nil
+}
+
+// reconnect dials the daemon again after the connection is broken
+func (signer *Signer) reconnect(ctx context.Context) error {
+	if signer.dial == nil {
+		return ErrClosed
+	}
+
+	conn, err := signer.dial(ctx)
+	if err != nil {
+		return err
+	}
+	publicKey, err := signer.connect(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if !publicKey.Equal(signer.publicKey) {
+		signer.disconnect()
+		return errors.Errorf("the signer key has changed from %s to %s", signer.publicKey, publicKey)
+	}
+	return nil
+}
+
+func (signer *Signer) disconnect() {
+	signer.conn.Close()
+	signer.conn, signer.reader = nil, nil
+}
+
+// PublicKey implements sign.Signer
+func (signer *Signer) PublicKey() types.PublicKey {
+	return signer.publicKey
+}
+
+// SignDigest implements sign.Signer
+func (signer *Signer) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	response, err := signer.call(ctx, Request{Method: MethodSignDigest, Digest: hex.EncodeToString(digest)})
+	if err != nil {
+		return nil, err
+	}
+	signature, err := hex.DecodeString(response.Signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid signature")
+	}
+	return signature, nil
+}
+
+// Close closes the connection to the signing daemon
+func (signer *Signer) Close() error {
+	signer.mutex.Lock()
+	defer signer.mutex.Unlock()
+	if signer.closed {
+		return ErrClosed
+	}
+	signer.closed = true
+	if signer.conn == nil {
+		return nil
+	}
+	return signer.conn.Close()
+}
+
+// call sends the request and reads the response, the broken connection is dialed again first
+func (signer *Signer) call(ctx context.Context, request Request) (*Response, error) {
+	signer.mutex.Lock()
+	defer signer.mutex.Unlock()
+	if signer.closed {
+		return nil, ErrClosed
+	}
+	if signer.conn == nil {
+		if err := signer.reconnect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := signer.roundTripContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.Errorf("remote signer: %s", response.Error)
+	}
+	return response, nil
+}
+
+// roundTripContext is roundTrip bound by the context. The connection is closed on an I/O error,
+// a late response would be read as the response of the next request otherwise.
+func (signer *Signer) roundTripContext(ctx context.Context, request Request) (*Response, error) {
+	// the deadline of the context is the deadline of the connection, the cancellation expires it
+	conn := signer.conn
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+	defer func() {
+		close(done)
+		<-stopped
+	}()
+
+	response, err := signer.roundTrip(request)
+	if err != nil {
+		signer.disconnect()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// the connection deadline may expire a moment before the context
+		if netErr, ok := errors.Cause(err).(net.Error); ok && netErr.Timeout() && !deadline.IsZero() {
+			return nil, context.DeadlineExceeded
+		}
+		return nil, err
+	}
+	return response, nil
+}
+
+func (signer *Signer) roundTrip(request Request) (*Response, error) {
+	if err := json.NewEncoder(signer.conn).Encode(request); err != nil {
+		return nil, errors.Wrap(err, "failed to send the request")
+	}
+
+	line, err := signer.reader.ReadBytes('\n')
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the response")
+	}
+	var response Response
+	if err := json.Unmarshal(line, &response); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the response")
+	}
+	return &response, nil
+}
+
+// Server serves the requests of the remote signers with a sign.Signer, it is run by the signing daemon
+type Server struct {
+	signer sign.Signer
+
+	mutex     sync.Mutex
+	listeners map[net.Listener]bool
+	conns     map[net.Conn]bool
+	closed    bool
+}
+
+// NewServer returns the server signing with the signer, e.g. a sign.WIFSigner
+func NewServer(signer sign.Signer) *Server {
+	return &Server{
+		signer:    signer,
+		listeners: make(map[net.Listener]bool),
+		conns:     make(map[net.Conn]bool),
+	}
+}
+
+// Serve accepts the connections until the listener or the server is closed.
+// The listener is closed when Serve returns.
+func (server *Server) Serve(listener net.Listener) error {
+	if !server.track(listener, nil) {
+		listener.Close()
+		return ErrClosed
+	}
+	defer func() {
+		server.untrack(listener, nil)
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if server.isClosed() {
+				return ErrClosed
+			}
+			return err
+		}
+		if !server.track(nil, conn) {
+			conn.Close()
+			return ErrClosed
+		}
+		go server.serveConn(conn)
+	}
+}
+
+// Close closes the listeners and the connections
+func (server *Server) Close() error {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if server.closed {
+		return ErrClosed
+	}
+	server.closed = true
+	for listener := range server.listeners {
+		listener.Close()
+	}
+	for conn := range server.conns {
+		conn.Close()
+	}
+	return nil
+}
+
+func (server *Server) serveConn(conn net.Conn) {
+	defer func() {
+		server.untrack(nil, conn)
+		conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+
+		var response Response
+		var request Request
+		if err := json.Unmarshal(line, &request); err != nil {
+			response.Error = "invalid request"
+		} else {
+			response = server.handle(request)
+		}
+
+		if err := encoder.Encode(response); err != nil {
+			return
+		}
+	}
+}
+
+func (server *Server) handle(request Request) Response {
+	switch request.Method {
+	case MethodPublicKey:
+		return Response{PublicKey: server.signer.PublicKey().String()}
+
+	case MethodSignDigest:
+		digest, err := hex.DecodeString(request.Digest)
+		if err != nil || len(digest) != 32 {
+			return Response{Error: "invalid digest"}
+		}
+		signature, err := server.signer.SignDigest(context.Background(), digest)
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{Signature: hex.EncodeToString(signature)}
+
+	default:
+		return Response{Error: "unknown method " + request.Method}
+	}
+}
+
+func (server *Server) track(listener net.Listener, conn net.Conn) bool {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if server.closed {
+		return false
+	}
+	if listener != nil {
+		server.listeners[listener] = true
+	}
+	if conn != nil {
+		server.conns[conn] = true
+	}
+	return true
+}
+
+func (server *Server) untrack(listener net.Listener, conn net.Conn) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	delete(server.listeners, listener)
+	delete(server.conns, conn)
+}
+
+func (server *Server) isClosed() bool {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.closed
+}
@@ -0,0 +1,184 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scorum/bitshares-go/sign"
+	"github.com/scorum/bitshares-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chainID = "4018d7844c78f6a6c41c6a552b898022310fc5dec06da467ee7905a8dad512c8"
+	wif     = "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"
+)
+
+// startDaemon serves the WIF signer on a unix socket, it returns the socket path
+func startDaemon(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "signer")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "signer.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	wifSigner, err := sign.NewWIFSigner(wif)
+	require.NoError(t, err)
+
+	server := NewServer(wifSigner)
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+	t.Cleanup(func() {
+		server.Close()
+		<-served
+	})
+	return path
+}
+
+func TestSigner(t *testing.T) {
+	path := startDaemon(t)
+
+	signer, err := Dial("unix", path)
+	require.NoError(t, err)
+	defer signer.Close()
+	require.Equal(t, "BTS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV", signer.PublicKey().String())
+
+	tx := &types.Transaction{
+		RefBlockNum:    12345,
+		RefBlockPrefix: 2828765431,
+		Expiration:     types.NewTime(time.Date(2018, 6, 6, 10, 0, 0, 0, time.UTC)),
+	}
+	tx.PushOperation(types.NewTransferOperation(
+		types.MustParseObjectID("1.2.1144"),
+		types.MustParseObjectID("1.2.1145"),
+		types.AssetAmount{AssetID: types.MustParseObjectID("1.3.0"), Amount: 1000},
+		types.AssetAmount{AssetID: types.MustParseObjectID("1.3.0"), Amount: 2000},
+	))
+	stx := sign.NewSignedTransaction(tx)
+	require.NoError(t, stx.SignWith(context.Background(), chainID, signer))
+	require.NoError(t, stx.Verify(chainID, signer.PublicKey()))
+
+	// the same signature as the local key, RFC 6979 is deterministic
+	local := sign.NewSignedTransaction(tx)
+	require.NoError(t, local.Sign([]string{wif}, chainID))
+	require.Equal(t, local.Signatures, stx.Signatures)
+
+	// the key of the daemon has to be of the chain
+	_, err = Dial("unix", path, WithPrefix("TEST"))
+	require.Error(t, err)
+	prefixed, err := Dial("unix", path, WithPrefix("BTS"))
+	require.NoError(t, err)
+	defer prefixed.Close()
+	require.Equal(t, "BTS", prefixed.PublicKey().Prefix())
+
+	// the daemon validates the requests
+	_, err = signer.SignDigest(context.Background(), []byte{1, 2, 3})
+	require.Error(t, err)
+
+	require.NoError(t, signer.Close())
+	_, err = signer.SignDigest(context.Background(), make([]byte, 32))
+	require.Equal(t, ErrClosed, err)
+}
+
+func TestSigner_Context(t *testing.T) {
+	// a daemon which never responds to the signing requests of the first connection
+	dir, err := ioutil.TempDir("", "signer")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	listener, err := net.Listen("unix", filepath.Join(dir, "signer.sock"))
+	require.NoError(t, err)
+	defer listener.Close()
+
+	wifSigner, err := sign.NewWIFSigner(wif)
+	require.NoError(t, err)
+	server := NewServer(wifSigner)
+
+	hang := make(chan struct{})
+	defer close(hang)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		reader.ReadBytes('\n')
+		conn.Write([]byte(`{"public_key": "BTS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV"}` + "\n"))
+		reader.ReadBytes('\n')
+		<-hang
+	}()
+
+	signer, err := Dial("unix", listener.Addr().String())
+	require.NoError(t, err)
+	defer signer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = signer.SignDigest(ctx, make([]byte, 32))
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	// the broken connection is dialed again
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		server.serveConn(conn)
+	}()
+	signature, err := signer.SignDigest(context.Background(), make([]byte, 32))
+	require.NoError(t, err)
+	require.Len(t, signature, 65)
+}
+
+func TestNewSigner_Broken(t *testing.T) {
+	client, daemon := net.Pipe()
+	go func() {
+		reader := bufio.NewReader(daemon)
+		reader.ReadBytes('\n')
+		daemon.Write([]byte(`{"public_key": "BTS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV"}` + "\n"))
+		daemon.Close()
+	}()
+
+	signer, err := NewSigner(context.Background(), client)
+	require.NoError(t, err)
+	defer signer.Close()
+
+	_, err = signer.SignDigest(context.Background(), make([]byte, 32))
+	require.Error(t, err)
+
+	// the signer can't dial the connection again
+	_, err = signer.SignDigest(context.Background(), make([]byte, 32))
+	require.Equal(t, ErrClosed, err)
+}
+
+func TestServer_Errors(t *testing.T) {
+	path := startDaemon(t)
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for request, response := range map[string]string{
+		`not json`:                  `{"error":"invalid request"}`,
+		`{"method": "unknown"}`:     `{"error":"unknown method unknown"}`,
+		`{"method": "sign_digest"}`: `{"error":"invalid digest"}`,
+		`{"method": "sign_digest", "digest": "` + hex.EncodeToString(make([]byte, 16)) + `"}`: `{"error":"invalid digest"}`,
+	} {
+		_, err := conn.Write([]byte(request + "\n"))
+		require.NoError(t, err)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		require.JSONEq(t, response, line, request)
+	}
+}
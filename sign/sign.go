@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 	"github.com/scorum/bitshares-go/sign/rfc6979"
-	"log"
 	"math/big"
 
 	secp256k1 "github.com/btcsuite/btcd/btcec"
@@ -23,7 +22,6 @@ func SignBufferSha256(bufSha256 []byte, privateKey *ecdsa.PrivateKey) []byte {
 		r, s, err := rfc6979.SignECDSA(privateKey, buf_sha256_clone, sha256.New, nonce)
 		nonce++
 		if err != nil {
-			log.Println(err)
 			return nil
 		}
 
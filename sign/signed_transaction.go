@@ -2,14 +2,12 @@ package sign
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"github.com/scorum/bitshares-go/encoding/transaction"
 	"github.com/scorum/bitshares-go/types"
-	"log"
 
-	"github.com/btcsuite/btcd/btcec"
-	"github.com/btcsuite/btcutil"
 	"github.com/pkg/errors"
 )
 
@@ -54,36 +52,16 @@ func (tx *SignedTransaction) Digest(chain string) ([]byte, error) {
 		return nil, errors.Wrap(err, "failed to write serialized transaction")
 	}
 
-	msgBytes := msgBuffer.Bytes()
-	message := hex.EncodeToString(msgBytes)
-	log.Printf("[DEBUG] Digest final message:%s\n", message)
-
 	// Compute the digest.
-	digest := sha256.Sum256(msgBytes)
+	digest := sha256.Sum256(msgBuffer.Bytes())
 	return digest[:], nil
 }
 
+// Sign signs the transaction with the private keys in the wallet import format, see SignWith
 func (tx *SignedTransaction) Sign(wifs []string, chain string) error {
-	digest, err := tx.Digest(chain)
+	signers, err := WIFSigners(wifs...)
 	if err != nil {
 		return err
 	}
-
-	privKeys := make([]*btcec.PrivateKey, len(wifs))
-	for index, wif := range wifs {
-		w, err := btcutil.DecodeWIF(wif)
-		if err != nil {
-			return err
-		}
-		privKeys[index] = w.PrivKey
-	}
-
-	// Set the signature array in the transaction.
-	sigsHex := make([]string, len(privKeys))
-	for index, privKey := range privKeys {
-		sig := SignBufferSha256(digest, privKey.ToECDSA())
-		sigsHex[index] = hex.EncodeToString(sig)
-	}
-	tx.Transaction.Signatures = sigsHex
-	return nil
+	return tx.SignWith(context.Background(), chain, signers...)
 }
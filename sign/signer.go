@@ -0,0 +1,105 @@
+package sign
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil"
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/types"
+)
+
+// Signer signs the transaction digests with a private key it holds,
+// so the key doesn't have to be in the memory of the application
+type Signer interface {
+	// PublicKey returns the public key of the signing key
+	PublicKey() types.PublicKey
+
+	// SignDigest returns the 65 bytes compact canonical signature of the SHA-256 digest
+	SignDigest(ctx context.Context, digest []byte) ([]byte, error)
+}
+
+// WIFSigner is a Signer holding the private key in the memory
+type WIFSigner struct {
+	privateKey *btcec.PrivateKey
+	publicKey  types.PublicKey
+}
+
+// NewWIFSigner returns the signer of the private key in the wallet import format
+func NewWIFSigner(wif string) (*WIFSigner, error) {
+	w, err := btcutil.DecodeWIF(wif)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode WIF")
+	}
+	return &WIFSigner{
+		privateKey: w.PrivKey,
+		publicKey:  types.NewPublicKey(w.PrivKey.PubKey(), types.DefaultAddressPrefix),
+	}, nil
+}
+
+// WithPrefix returns the signer of the same key with the chain prefix of the public key,
+// e.g. database.Config.GrapheneAddressPrefix
+func (signer *WIFSigner) WithPrefix(prefix string) *WIFSigner {
+	return &WIFSigner{privateKey: signer.privateKey, publicKey: signer.publicKey.WithPrefix(prefix)}
+}
+
+// PublicKey implements Signer
+func (signer *WIFSigner) PublicKey() types.PublicKey {
+	return signer.publicKey
+}
+
+// SignDigest implements Signer
+func (signer *WIFSigner) SignDigest(ctx context.Context, digest []byte) ([]byte, error) {
+	signature := SignBufferSha256(digest, signer.privateKey.ToECDSA())
+	if signature == nil {
+		return nil, errors.New("failed to sign the digest")
+	}
+	return signature, nil
+}
+
+// WIFSigners returns the signers of the private keys in the wallet import format
+func WIFSigners(wifs ...string) ([]Signer, error) {
+	signers := make([]Signer, len(wifs))
+	for i, wif := range wifs {
+		signer, err := NewWIFSigner(wif)
+		if err != nil {
+			return nil, err
+		}
+		signers[i] = signer
+	}
+	return signers, nil
+}
+
+// SignWith signs the transaction with the signers, replacing the signatures.
+// Every signature is checked to be canonical and to belong to the key of its signer,
+// so a faulty remote signer can't make the transaction invalid.
+func (tx *SignedTransaction) SignWith(ctx context.Context, chain string, signers ...Signer) error {
+	digest, err := tx.Digest(chain)
+	if err != nil {
+		return err
+	}
+
+	signatures := make([]string, len(signers))
+	for i, signer := range signers {
+		signature, err := signer.SignDigest(ctx, digest)
+		if err != nil {
+			return errors.Wrapf(err, "failed to sign with %s", signer.PublicKey())
+		}
+		if !IsCanonical(signature) {
+			return errors.Wrapf(ErrNonCanonicalSignature, "signed with %s", signer.PublicKey())
+		}
+
+		key, err := RecoverPublicKey(digest, signature)
+		if err != nil {
+			return errors.Wrapf(err, "signed with %s", signer.PublicKey())
+		}
+		if !key.IsEqual(signer.PublicKey().Key()) {
+			return errors.Errorf("the signature doesn't belong to %s", signer.PublicKey())
+		}
+		signatures[i] = hex.EncodeToString(signature)
+	}
+
+	tx.Transaction.Signatures = signatures
+	return nil
+}
@@ -0,0 +1,19 @@
+package sign
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWIFSigner(t *testing.T) {
+	signer, err := NewWIFSigner(otherWIF)
+	require.NoError(t, err)
+	require.Equal(t, "BTS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV", signer.PublicKey().String())
+
+	testnet := signer.WithPrefix("TEST")
+	require.Equal(t, "TEST6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV", testnet.PublicKey().String())
+
+	_, err = NewWIFSigner("invalid")
+	require.Error(t, err)
+}
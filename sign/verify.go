@@ -0,0 +1,129 @@
+package sign
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/types"
+)
+
+// signatureLength is the length of a compact signature: the recovery header followed by R and S
+const signatureLength = 65
+
+var (
+	// ErrNonCanonicalSignature is returned for a signature the nodes reject, see IsCanonical
+	ErrNonCanonicalSignature = errors.New("non-canonical signature")
+
+	// ErrMissingSignature is returned by Verify when an expected key has not signed the transaction
+	ErrMissingSignature = errors.New("missing signature")
+
+	// ErrUnexpectedSignature is returned by Verify when the transaction is signed by a key which is not expected
+	ErrUnexpectedSignature = errors.New("unexpected signature")
+
+	// ErrDuplicateSignature is returned when the transaction is signed twice by the same key,
+	// the nodes reject such a transaction with duplicate_signature
+	ErrDuplicateSignature = errors.New("duplicate signature")
+)
+
+// IsCanonical reports whether the compact signature is canonical the way Graphene requires:
+// neither R nor S have the high bit set, and none of them is padded with a needless zero byte
+func IsCanonical(signature []byte) bool {
+	if len(signature) != signatureLength {
+		return false
+	}
+	return signature[1]&0x80 == 0 &&
+		!(signature[1] == 0 && signature[2]&0x80 == 0) &&
+		signature[33]&0x80 == 0 &&
+		!(signature[33] == 0 && signature[34]&0x80 == 0)
+}
+
+// RecoverPublicKey recovers the public key from the compact signature of the digest
+func RecoverPublicKey(digest []byte, signature []byte) (*btcec.PublicKey, error) {
+	if len(signature) != signatureLength {
+		return nil, errors.Errorf("invalid signature length %d", len(signature))
+	}
+
+	// the header is 27 + the recovery ID, + 4 for the compressed keys
+	header := int(signature[0]) - 27
+	if header < 0 || header > 7 {
+		return nil, errors.Errorf("invalid signature header %d", signature[0])
+	}
+
+	sig := &btcec.Signature{
+		R: new(big.Int).SetBytes(signature[1:33]),
+		S: new(big.Int).SetBytes(signature[33:]),
+	}
+	key, err := recoverKeyFromSignature(btcec.S256(), sig, digest, header&3, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to recover the public key")
+	}
+
+	// make sure the signature is valid for the recovered key
+	if !sig.Verify(digest, key) {
+		return nil, errors.New("invalid signature")
+	}
+	return key, nil
+}
+
+// PublicKeys recovers the public keys from every signature of the transaction,
+// the keys have the BitShares prefix. ErrDuplicateSignature is returned when two signatures
+// recover to the same key.
+func (tx *SignedTransaction) PublicKeys(chain string) ([]types.PublicKey, error) {
+	digest, err := tx.Digest(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]types.PublicKey, len(tx.Signatures))
+	for i, signatureHex := range tx.Signatures {
+		signature, err := hex.DecodeString(signatureHex)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode signature %d", i)
+		}
+		if !IsCanonical(signature) {
+			return nil, errors.Wrapf(ErrNonCanonicalSignature, "signature %d", i)
+		}
+
+		key, err := RecoverPublicKey(digest, signature)
+		if err != nil {
+			return nil, errors.Wrapf(err, "signature %d", i)
+		}
+		keys[i] = types.NewPublicKey(key, types.DefaultAddressPrefix)
+		if containsKey(keys[:i], keys[i]) {
+			return nil, errors.Wrapf(ErrDuplicateSignature, "signature %d of %s", i, keys[i])
+		}
+	}
+	return keys, nil
+}
+
+// Verify checks the transaction is signed by all the expected keys and by them only.
+// The prefixes of the keys are ignored.
+func (tx *SignedTransaction) Verify(chain string, expected ...types.PublicKey) error {
+	keys, err := tx.PublicKeys(chain)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if !containsKey(expected, key) {
+			return errors.Wrap(ErrUnexpectedSignature, key.String())
+		}
+	}
+	for _, key := range expected {
+		if !containsKey(keys, key) {
+			return errors.Wrap(ErrMissingSignature, key.String())
+		}
+	}
+	return nil
+}
+
+func containsKey(keys []types.PublicKey, key types.PublicKey) bool {
+	for _, k := range keys {
+		if k.Equal(key) {
+			return true
+		}
+	}
+	return false
+}
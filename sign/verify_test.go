@@ -0,0 +1,121 @@
+package sign
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/types"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chainID   = "4018d7844c78f6a6c41c6a552b898022310fc5dec06da467ee7905a8dad512c8"
+	firstWIF  = "5JWHY5DxTF6qN5grTtChDCYBmWHfY9zaSsw4CxEKN5eZpH9iBma"
+	secondWIF = "5KPipdRzoxrp6dDqsBfMD6oFZG356trVHV5QBGx3rABs1zzWWs8"
+	otherWIF  = "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"
+)
+
+func publicKey(t *testing.T, wif string) types.PublicKey {
+	w, err := btcutil.DecodeWIF(wif)
+	require.NoError(t, err)
+	return types.NewPublicKey(w.PrivKey.PubKey(), "")
+}
+
+func newTestTransaction(t *testing.T) *SignedTransaction {
+	tx := &types.Transaction{
+		RefBlockNum:    12345,
+		RefBlockPrefix: 2828765431,
+		Expiration:     types.NewTime(time.Date(2018, 6, 6, 10, 0, 0, 0, time.UTC)),
+	}
+	tx.PushOperation(types.NewTransferOperation(
+		types.MustParseObjectID("1.2.1144"),
+		types.MustParseObjectID("1.2.1145"),
+		types.AssetAmount{AssetID: types.MustParseObjectID("1.3.0"), Amount: 1000},
+		types.AssetAmount{AssetID: types.MustParseObjectID("1.3.0"), Amount: 2000},
+	))
+	return NewSignedTransaction(tx)
+}
+
+func TestSignedTransaction_Verify(t *testing.T) {
+	stx := newTestTransaction(t)
+	require.NoError(t, stx.Sign([]string{firstWIF, secondWIF}, chainID))
+
+	for _, signature := range stx.Signatures {
+		b, err := hex.DecodeString(signature)
+		require.NoError(t, err)
+		require.True(t, IsCanonical(b))
+	}
+
+	keys, err := stx.PublicKeys(chainID)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.True(t, keys[0].Equal(publicKey(t, firstWIF)))
+	require.True(t, keys[1].Equal(publicKey(t, secondWIF)))
+
+	require.NoError(t, stx.Verify(chainID, publicKey(t, secondWIF), publicKey(t, firstWIF)))
+
+	err = stx.Verify(chainID, publicKey(t, firstWIF))
+	require.Equal(t, ErrUnexpectedSignature, errors.Cause(err))
+
+	err = stx.Verify(chainID, publicKey(t, firstWIF), publicKey(t, secondWIF), publicKey(t, otherWIF))
+	require.Equal(t, ErrMissingSignature, errors.Cause(err))
+
+	// signed twice by the same key
+	tx := *stx.Transaction
+	tx.Signatures = append(append([]string{}, stx.Signatures...), stx.Signatures[0])
+	duplicate := NewSignedTransaction(&tx)
+	_, err = duplicate.PublicKeys(chainID)
+	require.Equal(t, ErrDuplicateSignature, errors.Cause(err))
+	err = duplicate.Verify(chainID, publicKey(t, firstWIF), publicKey(t, secondWIF))
+	require.Equal(t, ErrDuplicateSignature, errors.Cause(err))
+
+	// another chain
+	err = stx.Verify("39f5e2ede1f8bc1a3a54a7914414e3779e33193f1f5693510e73cb7a87617447", publicKey(t, firstWIF), publicKey(t, secondWIF))
+	require.Error(t, err)
+
+	// a modified transaction
+	stx.RefBlockNum++
+	err = stx.Verify(chainID, publicKey(t, firstWIF), publicKey(t, secondWIF))
+	require.Error(t, err)
+}
+
+func TestRecoverPublicKey(t *testing.T) {
+	stx := newTestTransaction(t)
+	require.NoError(t, stx.Sign([]string{otherWIF}, chainID))
+
+	digest, err := stx.Digest(chainID)
+	require.NoError(t, err)
+	signature, err := hex.DecodeString(stx.Signatures[0])
+	require.NoError(t, err)
+
+	key, err := RecoverPublicKey(digest, signature)
+	require.NoError(t, err)
+	require.True(t, publicKey(t, otherWIF).Key().IsEqual(key))
+
+	_, err = RecoverPublicKey(digest, signature[1:])
+	require.Error(t, err)
+
+	invalid := append([]byte{}, signature...)
+	invalid[0] = 1
+	_, err = RecoverPublicKey(digest, invalid)
+	require.Error(t, err)
+}
+
+func TestIsCanonical(t *testing.T) {
+	signature := make([]byte, 65)
+	signature[1], signature[33] = 0x7f, 0x7f
+	require.True(t, IsCanonical(signature))
+
+	highR := append([]byte{}, signature...)
+	highR[1] = 0x80
+	require.False(t, IsCanonical(highR))
+
+	paddedS := append([]byte{}, signature...)
+	paddedS[33], paddedS[34] = 0, 0x7f
+	require.False(t, IsCanonical(paddedS))
+
+	require.False(t, IsCanonical(signature[:64]))
+}
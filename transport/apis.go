@@ -0,0 +1,79 @@
+package transport
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/scorum/bitshares-go/caller"
+)
+
+// LoginAPIID is the ID of the login_api which is always available on a fresh connection
+const LoginAPIID caller.APIID = 1
+
+// apiNames lists the login_api methods returning an API ID
+var apiNames = map[string]bool{
+	"database":          true,
+	"history":           true,
+	"network_broadcast": true,
+	"network_node":      true,
+	"crypto":            true,
+	"asset":             true,
+	"orders":            true,
+	"block":             true,
+}
+
+// APIName returns the name of the API requested by the login_api method,
+// e.g. database() or get_api_by_name("history")
+func APIName(method string, args []interface{}) (string, bool) {
+	if apiNames[method] {
+		return method, true
+	}
+	if method == "get_api_by_name" && len(args) == 1 {
+		name, ok := args[0].(string)
+		return name, ok
+	}
+	return "", false
+}
+
+// APIs assigns the IDs to the APIs by name for the transports resolving the API ID requests
+// themselves, e.g. when every request is a separate session on the node side. It is safe for concurrent use.
+type APIs struct {
+	mutex sync.Mutex
+	names map[caller.APIID]string
+	ids   map[string]caller.APIID
+}
+
+// NewAPIs creates an empty API registry
+func NewAPIs() *APIs {
+	return &APIs{
+		names: make(map[caller.APIID]string),
+		ids:   make(map[string]caller.APIID),
+	}
+}
+
+// Resolve assigns an ID to the API with the given name, the same name always gets the same ID.
+// The ID is unmarshalled into the reply the same way as the response of the node.
+func (apis *APIs) Resolve(name string, reply interface{}) error {
+	apis.mutex.Lock()
+	id, ok := apis.ids[name]
+	if !ok {
+		id = LoginAPIID + caller.APIID(len(apis.ids)) + 1
+		apis.ids[name] = id
+		apis.names[id] = name
+	}
+	apis.mutex.Unlock()
+
+	if reply == nil {
+		return nil
+	}
+	raw, _ := json.Marshal(id)
+	return json.Unmarshal(raw, reply)
+}
+
+// Name returns the name of the API with the given ID
+func (apis *APIs) Name(id caller.APIID) (string, bool) {
+	apis.mutex.Lock()
+	defer apis.mutex.Unlock()
+	name, ok := apis.names[id]
+	return name, ok
+}
@@ -0,0 +1,122 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The kinds of the errors returned by the node.
+// An *RPCError matches its kind with errors.Is:
+//
+//	if errors.Is(err, transport.ErrInsufficientBalance) {
+//		// top up the account
+//	}
+var (
+	ErrInsufficientBalance  = errors.New("insufficient balance")
+	ErrMissingAuthority     = errors.New("missing required authority")
+	ErrExpiredTransaction   = errors.New("transaction expired")
+	ErrDuplicateTransaction = errors.New("duplicate transaction")
+	ErrUnknownAccount       = errors.New("unknown account")
+	ErrUnknownAsset         = errors.New("unknown asset")
+	ErrFeeTooLow            = errors.New("fee too low")
+)
+
+// errorKind matches an error kind either by the Graphene exception name or by the assertion message
+type errorKind struct {
+	err     error
+	names   []string
+	pattern *regexp.Regexp
+}
+
+// errorKinds are checked in order, the first match wins
+var errorKinds = []errorKind{
+	{
+		err:     ErrDuplicateTransaction,
+		names:   []string{"duplicate_transaction"},
+		pattern: regexp.MustCompile(`(?i)duplicate transaction|is already in the database`),
+	},
+	{
+		err:     ErrMissingAuthority,
+		names:   []string{"tx_missing_active_auth", "tx_missing_owner_auth", "tx_missing_other_auth"},
+		pattern: regexp.MustCompile(`(?i)missing (required )?(active|owner|other) auth`),
+	},
+	{
+		err:     ErrExpiredTransaction,
+		names:   []string{"tx_expired"},
+		pattern: regexp.MustCompile(`(?i)now <=? trx\.expiration|transaction (has )?expired`),
+	},
+	{
+		err:     ErrFeeTooLow,
+		names:   []string{"insufficient_fee"},
+		pattern: regexp.MustCompile(`(?i)insufficient fee|core_fee_paid >= required_core_fee`),
+	},
+	{
+		err:     ErrInsufficientBalance,
+		names:   []string{"insufficient_balance"},
+		pattern: regexp.MustCompile(`(?i)insufficient balance`),
+	},
+	{
+		err:     ErrUnknownAccount,
+		pattern: regexp.MustCompile(`(?i)no such account|unknown account|account \S* ?(was )?not found|unable to find account`),
+	},
+	{
+		err:     ErrUnknownAsset,
+		pattern: regexp.MustCompile(`(?i)no such asset|unknown asset|asset \S* ?(was )?not found|unable to find asset`),
+	},
+}
+
+var placeholder = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// Kind returns the kind of the error, one of the Err* variables, or nil if the error is not recognized
+func (e *RPCError) Kind() error {
+	for _, kind := range errorKinds {
+		for _, name := range kind.names {
+			if e.Data.Name == name {
+				return kind.err
+			}
+		}
+	}
+
+	text := strings.Join(append([]string{e.Message}, e.Details()...), "\n")
+	for _, kind := range errorKinds {
+		if kind.pattern.MatchString(text) {
+			return kind.err
+		}
+	}
+	return nil
+}
+
+// Is reports whether the error is of the target kind
+func (e *RPCError) Is(target error) bool {
+	kind := e.Kind()
+	return kind != nil && kind == target
+}
+
+// Details returns the messages of the exception stack with the placeholders substituted,
+// e.g. "Insufficient Balance: 10 BTS, unable to transfer '100 BTS' from account 'a' to 'b'"
+func (e *RPCError) Details() []string {
+	var details []string
+	for _, frame := range e.Data.Stack {
+		if frame.Format == "" {
+			continue
+		}
+		details = append(details, placeholder.ReplaceAllStringFunc(frame.Format, func(s string) string {
+			key := placeholder.FindStringSubmatch(s)[1]
+			v, ok := frame.Data[key]
+			if !ok {
+				return s
+			}
+			if str, ok := v.(string); ok {
+				return str
+			}
+			if raw, err := json.Marshal(v); err == nil {
+				return string(raw)
+			}
+			return fmt.Sprint(v)
+		}))
+	}
+	return details
+}
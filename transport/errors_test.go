@@ -0,0 +1,62 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const insufficientBalance = `{
+	"code": 1,
+	"message": "Assert Exception: insufficient_balance: Insufficient Balance: cali4889's balance of 0.5 TEST is less than required 10 TEST",
+	"data": {
+		"code": 10,
+		"name": "assert_exception",
+		"message": "Assert Exception",
+		"stack": [{
+			"context": {"level": "error", "file": "transfer_evaluator.cpp", "line": 52, "method": "do_evaluate"},
+			"format": "insufficient_balance: Insufficient Balance: ${balance}, unable to transfer '${total_transfer}' from account '${a}' to '${t}'",
+			"data": {"balance": "0.5 TEST", "total_transfer": "10 TEST", "a": "cali4889", "t": "cali4890"}
+		}, {
+			"context": {"level": "warn", "file": "db_block.cpp", "line": 650, "method": "apply_operation"},
+			"format": "",
+			"data": {"op": {"amount": 10}}
+		}]
+	}
+}`
+
+func TestRPCError_Kind(t *testing.T) {
+	var rpcErr RPCError
+	require.NoError(t, json.Unmarshal([]byte(insufficientBalance), &rpcErr))
+
+	require.Equal(t, ErrInsufficientBalance, rpcErr.Kind())
+	require.Equal(t, []string{"insufficient_balance: Insufficient Balance: 0.5 TEST, unable to transfer '10 TEST' from account 'cali4889' to 'cali4890'"}, rpcErr.Details())
+
+	// errors.Is and errors.As see through the wrapping
+	err := fmt.Errorf("transfer: %w", &rpcErr)
+	require.True(t, errors.Is(err, ErrInsufficientBalance))
+	require.False(t, errors.Is(err, ErrFeeTooLow))
+
+	var target *RPCError
+	require.True(t, errors.As(err, &target))
+	require.Equal(t, 10, target.Data.Code)
+
+	tests := []struct {
+		err  RPCError
+		kind error
+	}{
+		{RPCError{Data: RPCErrorData{Name: "tx_missing_active_auth"}}, ErrMissingAuthority},
+		{RPCError{Data: RPCErrorData{Name: "duplicate_transaction"}}, ErrDuplicateTransaction},
+		{RPCError{Message: "Assert Exception: now <= trx.expiration: "}, ErrExpiredTransaction},
+		{RPCError{Message: "Assert Exception: core_fee_paid >= required_core_fee: Insufficient Fee Paid"}, ErrFeeTooLow},
+		{RPCError{Message: "Assert Exception: account: no such account"}, ErrUnknownAccount},
+		{RPCError{Data: RPCErrorData{Stack: []RPCErrorFrame{{Format: "Asset ${asset} not found", Data: map[string]interface{}{"asset": "FOO"}}}}}, ErrUnknownAsset},
+		{RPCError{Message: "Assert Exception"}, nil},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.kind, test.err.Kind(), test.err.Error())
+	}
+}
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/logging"
+	"github.com/scorum/bitshares-go/metrics"
+	"github.com/scorum/bitshares-go/transport"
+)
+
+// ErrCallbacksNotSupported is returned by SetCallback, notices can't be delivered over plain HTTP
+var ErrCallbacksNotSupported = errors.New("callbacks are not supported by the HTTP transport")
+
+// Transport is a JSON-RPC over HTTP POST transport. It implements caller.CallCloser.
+type Transport struct {
+	url     string
+	client  *http.Client
+	logger  logging.Logger
+	metrics metrics.Collector
+
+	requestID uint64
+	closing   bool
+
+	// every HTTP request is a separate session on the node side, so the IDs
+	// are assigned by the transport and the APIs are addressed by name instead
+	apis *transport.APIs
+
+	mutex sync.Mutex
+}
+
+// Option configures the Transport
+type Option func(*Transport)
+
+// WithHTTPClient sets the HTTP client used to make the requests, http.DefaultClient is used by default
+func WithHTTPClient(client *http.Client) Option {
+	return func(t *Transport) {
+		t.client = client
+	}
+}
+
+// WithLogger sets the logger, the transport is silent by default
+func WithLogger(logger logging.Logger) Option {
+	return func(t *Transport) {
+		t.logger = logger
+	}
+}
+
+// WithMetrics sets the collector of the call metrics
+func WithMetrics(collector metrics.Collector) Option {
+	return func(t *Transport) {
+		t.metrics = collector
+	}
+}
+
+func NewTransport(url string, options ...Option) (*Transport, error) {
+	t := &Transport{
+		url:     url,
+		client:  http.DefaultClient,
+		logger:  logging.Nop,
+		metrics: metrics.Nop,
+		apis:    transport.NewAPIs(),
+	}
+
+	for _, option := range options {
+		option(t)
+	}
+	return t, nil
+}
+
+func (t *Transport) Call(api caller.APIID, method string, args []interface{}, reply interface{}) error {
+	return t.CallContext(context.Background(), api, method, args, reply)
+}
+
+// CallContext implements caller.ContextCaller
+func (t *Transport) CallContext(ctx context.Context, api caller.APIID, method string, args []interface{}, reply interface{}) error {
+	t.mutex.Lock()
+	if t.closing {
+		t.mutex.Unlock()
+		return transport.ErrShutdown
+	}
+	t.requestID++
+	seq := t.requestID
+	t.mutex.Unlock()
+
+	// API ID requests are resolved locally
+	if api == transport.LoginAPIID {
+		if apiName, ok := transport.APIName(method, args); ok {
+			return t.apis.Resolve(apiName, reply)
+		}
+	}
+	name, named := t.apis.Name(api)
+
+	var target interface{} = api
+	label := strconv.Itoa(int(api))
+	if named {
+		target = name
+		label = name
+	}
+	if api == transport.LoginAPIID {
+		label = "login"
+	}
+
+	t.metrics.CallStarted(label, method)
+	start := time.Now()
+	err := t.post(ctx, seq, target, method, args, reply)
+	t.metrics.CallFinished(label, method, time.Since(start), err)
+	if err != nil {
+		t.logger.Debug("call failed", "url", t.url, "api", label, "method", method, "error", err)
+	}
+	return err
+}
+
+// post sends the call and decodes the response
+func (t *Transport) post(ctx context.Context, seq uint64, target interface{}, method string, args []interface{}, reply interface{}) error {
+	request := transport.RPCRequest{
+		Method: "call",
+		ID:     seq,
+		Params: []interface{}{target, method, args},
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal request")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response transport.RPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return errors.Wrapf(err, "failed to decode response, status %s", resp.Status)
+	}
+
+	if response.Error != nil {
+		return response.Error
+	}
+
+	if reply != nil && response.Result != nil {
+		if err := json.Unmarshal(*response.Result, reply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetCallback always fails with ErrCallbacksNotSupported
+func (t *Transport) SetCallback(api caller.APIID, method string, notice func(args json.RawMessage)) error {
+	return ErrCallbacksNotSupported
+}
+
+// Close marks the transport as closed, every subsequent call returns ErrShutdown.
+func (t *Transport) Close() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.closing {
+		return transport.ErrShutdown
+	}
+	t.closing = true
+	return nil
+}
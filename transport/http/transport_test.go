@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/transport"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		require.Equal(t, "call", request.Method)
+
+		var method string
+		json.Unmarshal(request.Params[1], &method)
+
+		response := map[string]interface{}{"id": request.ID}
+		switch method {
+		case "get_api":
+			// echo the API the call was addressed to
+			response["result"] = request.Params[0]
+		case "slow":
+			time.Sleep(time.Second)
+		default:
+			response["error"] = map[string]interface{}{"code": 1, "message": "unknown method"}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func TestTransport_Call(t *testing.T) {
+	server := newServer(t)
+	defer server.Close()
+
+	tr, err := NewTransport(server.URL)
+	require.NoError(t, err)
+
+	t.Run("APIs are addressed by name", func(t *testing.T) {
+		var databaseID caller.APIID
+		require.NoError(t, tr.Call(1, "database", []interface{}{}, &databaseID))
+
+		var historyID caller.APIID
+		require.NoError(t, tr.Call(1, "history", []interface{}{}, &historyID))
+		require.NotEqual(t, databaseID, historyID)
+
+		var again caller.APIID
+		require.NoError(t, tr.Call(1, "database", []interface{}{}, &again))
+		require.Equal(t, databaseID, again)
+
+		var api string
+		require.NoError(t, tr.Call(databaseID, "get_api", []interface{}{}, &api))
+		require.Equal(t, "database", api)
+	})
+
+	t.Run("RPC error", func(t *testing.T) {
+		err := tr.Call(1, "unknown", []interface{}{}, nil)
+		require.Error(t, err)
+		require.IsType(t, &transport.RPCError{}, err)
+	})
+
+	t.Run("context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.Error(t, tr.CallContext(ctx, 1, "slow", []interface{}{}, nil))
+	})
+
+	t.Run("callbacks", func(t *testing.T) {
+		require.Equal(t, ErrCallbacksNotSupported, tr.SetCallback(2, "set_block_applied_callback", nil))
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		require.NoError(t, tr.Close())
+		require.Equal(t, transport.ErrShutdown, tr.Call(1, "database", []interface{}{}, nil))
+	})
+}
@@ -0,0 +1,608 @@
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/apis/database"
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/caller/retry"
+	"github.com/scorum/bitshares-go/logging"
+	"github.com/scorum/bitshares-go/transport"
+	"github.com/scorum/bitshares-go/transport/http"
+	"github.com/scorum/bitshares-go/transport/websocket"
+)
+
+var (
+	// ErrNoNodes is returned when the pool has no node to route the call to
+	ErrNoNodes = errors.New("no available nodes")
+
+	// ErrChainIDMismatch is returned when a node reports a chain ID different from the other nodes
+	ErrChainIDMismatch = errors.New("chain ID mismatch")
+)
+
+// Pool routes the calls to the healthiest of the given nodes and fails over to the next one
+// when a node returns a transport error and the call is safe to repeat, see retry.Idempotent.
+// Only the nodes which reported the expected chain ID get the calls. A node is healthy if it responds and its head block
+// does not lag behind the best node more than MaxLag blocks. It implements caller.CallCloser.
+type Pool struct {
+	nodes    []*node
+	interval time.Duration
+	timeout  time.Duration
+	maxLag   uint32
+	chainID  string
+	logger   logging.Logger
+
+	apis          *transport.APIs // the IDs are assigned by the pool, the real ones are resolved on each node separately
+	handshake     []*loginCall
+	subscriptions []*subscription
+	active        *node // node holding the subscriptions
+
+	closing bool
+	done    chan struct{}
+
+	mutex sync.Mutex
+}
+
+type node struct {
+	index int
+	cc    caller.CallCloser
+
+	apis      map[string]caller.APIID
+	prepared  int // number of handshake calls replayed on the node
+	headBlock uint32
+	chainID   string
+	err       error // last error, nil if the node is healthy
+}
+
+// loginCall is a login_api call, e.g. login, replayed on every node
+type loginCall struct {
+	method string
+	args   []interface{}
+}
+
+// subscription is a callback registered through SetCallback
+type subscription struct {
+	api      caller.APIID
+	method   string
+	callback func(raw json.RawMessage)
+}
+
+// Option configures the Pool
+type Option func(*Pool)
+
+// WithLogger sets the logger, the pool is silent by default
+func WithLogger(logger logging.Logger) Option {
+	return func(p *Pool) {
+		p.logger = logger
+	}
+}
+
+// WithHealthCheckInterval sets how often the nodes are checked, 10 seconds by default
+func WithHealthCheckInterval(interval time.Duration) Option {
+	return func(p *Pool) {
+		p.interval = interval
+	}
+}
+
+// WithHealthCheckTimeout sets the timeout of a single node check, 5 seconds by default
+func WithHealthCheckTimeout(timeout time.Duration) Option {
+	return func(p *Pool) {
+		p.timeout = timeout
+	}
+}
+
+// WithChainID sets the expected chain ID, by default it is the chain ID of the first responding node
+func WithChainID(chainID string) Option {
+	return func(p *Pool) {
+		p.chainID = chainID
+	}
+}
+
+// WithMaxLag sets how many blocks a node might lag behind the best node and still be healthy, 5 by default
+func WithMaxLag(blocks uint32) Option {
+	return func(p *Pool) {
+		p.maxLag = blocks
+	}
+}
+
+// New creates a pool over the given nodes. The nodes are checked once before New returns:
+// at least one must be healthy and all of the responding nodes must report the same chain ID.
+func New(nodes []caller.CallCloser, options ...Option) (*Pool, error) {
+	if len(nodes) == 0 {
+		return nil, ErrNoNodes
+	}
+
+	p := &Pool{
+		interval: 10 * time.Second,
+		timeout:  5 * time.Second,
+		maxLag:   5,
+		logger:   logging.Nop,
+		apis:     transport.NewAPIs(),
+		done:     make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	for i, cc := range nodes {
+		p.nodes = append(p.nodes, &node{index: i, cc: cc, apis: make(map[string]caller.APIID)})
+	}
+
+	p.check()
+
+	p.mutex.Lock()
+	healthy := 0
+	for _, n := range p.nodes {
+		if errors.Cause(n.err) == ErrChainIDMismatch {
+			p.mutex.Unlock()
+			return nil, n.err
+		}
+		if n.err == nil {
+			healthy++
+		}
+	}
+	p.mutex.Unlock()
+
+	if healthy == 0 {
+		return nil, errors.Wrap(ErrNoNodes, "no healthy nodes")
+	}
+
+	go p.run()
+	return p, nil
+}
+
+// Dial creates a pool over the nodes with the given URLs, the transport of each node is chosen by the URL scheme
+// like bitshares.NewClient does and gets the logger of the pool. The unreachable nodes are skipped,
+// at least one has to be reachable.
+func Dial(urls []string, options ...Option) (*Pool, error) {
+	config := &Pool{logger: logging.Nop}
+	for _, option := range options {
+		option(config)
+	}
+
+	var nodes []caller.CallCloser
+	for _, url := range urls {
+		cc, err := dial(url, config.logger)
+		if err != nil {
+			config.logger.Warn("node is unreachable", "url", url, "error", err)
+			continue
+		}
+		nodes = append(nodes, cc)
+	}
+
+	p, err := New(nodes, options...)
+	if err != nil {
+		for _, cc := range nodes {
+			cc.Close()
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// dial creates the transport matching the URL scheme
+func dial(url string, logger logging.Logger) (caller.CallCloser, error) {
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		return http.NewTransport(url, http.WithLogger(logger))
+	}
+	return websocket.NewTransport(url, websocket.WithLogger(logger))
+}
+
+// ChainID returns the chain ID reported by the nodes
+func (p *Pool) ChainID() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.chainID
+}
+
+func (p *Pool) Call(api caller.APIID, method string, args []interface{}, reply interface{}) error {
+	return p.CallContext(context.Background(), api, method, args, reply)
+}
+
+// CallContext implements caller.ContextCaller. The call is made on the healthiest node,
+// on a transport error the next node is tried if the call is idempotent or has not been sent yet.
+// Errors returned by the node itself are not retried.
+func (p *Pool) CallContext(ctx context.Context, api caller.APIID, method string, args []interface{}, reply interface{}) error {
+	p.mutex.Lock()
+	if p.closing {
+		p.mutex.Unlock()
+		return transport.ErrShutdown
+	}
+	p.mutex.Unlock()
+
+	// API ID requests are resolved by the pool
+	if api == transport.LoginAPIID {
+		if name, ok := transport.APIName(method, args); ok {
+			return p.apis.Resolve(name, reply)
+		}
+	}
+
+	err := ErrNoNodes
+	for _, n := range p.candidates() {
+		var sent bool
+		sent, err = p.callNode(ctx, n, api, method, args, reply)
+		if err == nil {
+			if api == transport.LoginAPIID {
+				p.remember(n, method, args)
+			}
+			return nil
+		}
+		if !failover(ctx, err) {
+			return err
+		}
+		// a broadcast might have made it to the chain even though the response is lost
+		if sent && !retry.Idempotent(method) {
+			p.fail(n, err)
+			return err
+		}
+		p.fail(n, err)
+	}
+	return err
+}
+
+// callNode makes the call on the given node translating the API ID.
+// It reports whether the call itself has been sent, the preparation might fail before that.
+func (p *Pool) callNode(ctx context.Context, n *node, api caller.APIID, method string, args []interface{}, reply interface{}) (bool, error) {
+	if err := p.prepare(ctx, n); err != nil {
+		return false, err
+	}
+
+	nodeAPI, err := p.nodeAPI(ctx, n, api)
+	if err != nil {
+		return false, err
+	}
+	return true, caller.CallContext(ctx, n.cc, nodeAPI, method, args, reply)
+}
+
+// prepare replays the login calls the node has not seen yet
+func (p *Pool) prepare(ctx context.Context, n *node) error {
+	for {
+		p.mutex.Lock()
+		if n.prepared >= len(p.handshake) {
+			p.mutex.Unlock()
+			return nil
+		}
+		call := p.handshake[n.prepared]
+		p.mutex.Unlock()
+
+		if err := caller.CallContext(ctx, n.cc, transport.LoginAPIID, call.method, call.args, nil); err != nil {
+			return errors.Wrapf(err, "failed to replay %s", call.method)
+		}
+
+		p.mutex.Lock()
+		n.prepared++
+		p.mutex.Unlock()
+	}
+}
+
+// remember records the login call to replay it on the other nodes, a repeated call is recorded once
+func (p *Pool) remember(n *node, method string, args []interface{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, call := range p.handshake {
+		if call.method == method && fmt.Sprint(call.args) == fmt.Sprint(args) {
+			return
+		}
+	}
+	p.handshake = append(p.handshake, &loginCall{method: method, args: args})
+	if n.prepared == len(p.handshake)-1 {
+		n.prepared++
+	}
+}
+
+// nodeAPI translates the pool API ID into the ID of the API on the given node
+func (p *Pool) nodeAPI(ctx context.Context, n *node, api caller.APIID) (caller.APIID, error) {
+	name, ok := p.apis.Name(api)
+	if !ok {
+		return api, nil
+	}
+
+	p.mutex.Lock()
+	id, ok := n.apis[name]
+	p.mutex.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	if err := caller.CallContext(ctx, n.cc, transport.LoginAPIID, name, caller.EmptyParams, &id); err != nil {
+		return 0, errors.Wrapf(err, "failed to get %s API ID", name)
+	}
+
+	p.mutex.Lock()
+	n.apis[name] = id
+	p.mutex.Unlock()
+	return id, nil
+}
+
+// candidates returns the nodes ordered by preference: the healthy ones first,
+// then the failed ones as the last resort. A node is never a candidate until its chain ID has been verified,
+// a signed transaction must not reach a node on another chain.
+func (p *Pool) candidates() []*node {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	nodes := make([]*node, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		if n.chainID != "" && errors.Cause(n.err) != ErrChainIDMismatch {
+			nodes = append(nodes, n)
+		}
+	}
+
+	// the healthy nodes keep the given order, so the calls stick to the preferred node
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].err == nil && nodes[j].err != nil
+	})
+	return nodes
+}
+
+// fail marks the node as unhealthy until the next successful check
+func (p *Pool) fail(n *node, err error) {
+	p.mutex.Lock()
+	n.err = err
+	p.mutex.Unlock()
+	p.logger.Warn("node failed", "node", n.index, "error", err)
+}
+
+// failover reports whether the call should be retried on another node
+func failover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if _, ok := errors.Cause(err).(*transport.RPCError); ok {
+		return false
+	}
+	return true
+}
+
+func (p *Pool) run() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.check()
+		}
+	}
+}
+
+// check updates the health of every node and moves the subscriptions
+// to a healthy node if the current one has failed
+func (p *Pool) check() {
+	reported := make([]string, len(p.nodes))
+
+	var wg sync.WaitGroup
+	for i, n := range p.nodes {
+		wg.Add(1)
+		go func(i int, n *node) {
+			defer wg.Done()
+			reported[i] = p.checkNode(n)
+		}(i, n)
+	}
+	wg.Wait()
+
+	p.mutex.Lock()
+	// the chain ID is verified in the order the nodes were given,
+	// so the first node defines the chain unless it was set explicitly
+	for i, n := range p.nodes {
+		if reported[i] == "" {
+			continue
+		}
+		if p.chainID == "" {
+			p.chainID = reported[i]
+		}
+		if reported[i] != p.chainID {
+			n.err = errors.Wrapf(ErrChainIDMismatch, "node #%d is on chain %s, expected %s", n.index, reported[i], p.chainID)
+			p.logger.Error("node is excluded", "node", n.index, "error", n.err)
+			continue
+		}
+		n.chainID = reported[i]
+	}
+
+	var best uint32
+	for _, n := range p.nodes {
+		if n.err == nil && n.headBlock > best {
+			best = n.headBlock
+		}
+	}
+	for _, n := range p.nodes {
+		if n.err == nil && best-n.headBlock > p.maxLag {
+			n.err = errors.Errorf("head block %d lags behind %d", n.headBlock, best)
+		}
+	}
+	p.mutex.Unlock()
+
+	p.rebalance()
+}
+
+// checkNode requests the head block of the node.
+// It returns the chain ID reported by the node if it has not been verified yet.
+func (p *Pool) checkNode(n *node) (chainID string) {
+	p.mutex.Lock()
+	mismatch := errors.Cause(n.err) == ErrChainIDMismatch
+	verified := n.chainID != ""
+	p.mutex.Unlock()
+	if mismatch {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	err := func() error {
+		databaseID, err := p.nodeAPI(ctx, n, p.databaseID())
+		if err != nil {
+			return err
+		}
+		api := database.NewAPI(databaseID, n.cc)
+
+		if !verified {
+			id, err := api.GetChainIDContext(ctx)
+			if err != nil {
+				return errors.Wrap(err, "failed to get chain ID")
+			}
+			chainID = *id
+		}
+
+		props, err := api.GetDynamicGlobalPropertiesContext(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to get dynamic global properties")
+		}
+
+		p.mutex.Lock()
+		n.headBlock = props.HeadBlockNumber
+		p.mutex.Unlock()
+		return nil
+	}()
+
+	p.mutex.Lock()
+	n.err = err
+	p.mutex.Unlock()
+
+	if err != nil {
+		p.logger.Warn("node is unhealthy", "node", n.index, "error", err)
+		return ""
+	}
+	return chainID
+}
+
+// databaseID returns the pool ID of the database API
+func (p *Pool) databaseID() caller.APIID {
+	var id caller.APIID
+	p.apis.Resolve("database", &id)
+	return id
+}
+
+// SetCallback registers the callback on the healthiest node.
+// If the node fails the callbacks are registered again on another node.
+func (p *Pool) SetCallback(api caller.APIID, method string, callback func(raw json.RawMessage)) error {
+	s := &subscription{api: api, method: method, callback: callback}
+
+	p.mutex.Lock()
+	active := p.active
+	p.mutex.Unlock()
+
+	if active == nil {
+		nodes := p.candidates()
+		if len(nodes) == 0 {
+			return ErrNoNodes
+		}
+		active = nodes[0]
+	}
+
+	if err := p.subscribe(active, s); err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	p.active = active
+	p.subscriptions = append(p.subscriptions, s)
+	p.mutex.Unlock()
+	return nil
+}
+
+func (p *Pool) subscribe(n *node, s *subscription) error {
+	ctx := context.Background()
+	if err := p.prepare(ctx, n); err != nil {
+		return err
+	}
+
+	api, err := p.nodeAPI(ctx, n, s.api)
+	if err != nil {
+		return err
+	}
+	return n.cc.SetCallback(api, s.method, s.callback)
+}
+
+// rebalance moves the subscriptions off a failed node
+func (p *Pool) rebalance() {
+	p.mutex.Lock()
+	active := p.active
+	subscriptions := append([]*subscription{}, p.subscriptions...)
+	p.mutex.Unlock()
+
+	if active == nil || len(subscriptions) == 0 {
+		return
+	}
+
+	p.mutex.Lock()
+	failed := active.err != nil
+	p.mutex.Unlock()
+	if !failed {
+		return
+	}
+
+	for _, n := range p.candidates() {
+		if n == active {
+			continue
+		}
+
+		var err error
+		for _, s := range subscriptions {
+			if err = p.subscribe(n, s); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			p.fail(n, err)
+			continue
+		}
+
+		p.cancelSubscriptions(active)
+
+		p.mutex.Lock()
+		p.active = n
+		p.mutex.Unlock()
+		return
+	}
+}
+
+// cancelSubscriptions drops the subscriptions of the node the pool has moved away from.
+// The transport drops them locally, so it does not register them again once the node is back,
+// and the node itself is asked to stop sending the notices in case it is still alive.
+func (p *Pool) cancelSubscriptions(n *node) {
+	if resetter, ok := n.cc.(caller.CallbackResetter); ok {
+		resetter.ResetCallbacks()
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+		defer cancel()
+
+		databaseID, err := p.nodeAPI(ctx, n, p.databaseID())
+		if err == nil {
+			database.NewAPI(databaseID, n.cc).CancelAllSubscriptionsContext(ctx)
+		}
+	}()
+}
+
+// Close closes all of the nodes
+func (p *Pool) Close() error {
+	p.mutex.Lock()
+	if p.closing {
+		p.mutex.Unlock()
+		return transport.ErrShutdown
+	}
+	p.closing = true
+	close(p.done)
+	p.mutex.Unlock()
+
+	var result error
+	for _, n := range p.nodes {
+		if err := n.cc.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
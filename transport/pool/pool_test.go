@@ -0,0 +1,371 @@
+package pool
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/apis/database"
+	"github.com/scorum/bitshares-go/apis/login"
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/logging"
+	"github.com/scorum/bitshares-go/nodetest"
+	"github.com/scorum/bitshares-go/transport"
+	"github.com/scorum/bitshares-go/transport/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+var errConnection = errors.New("connection reset")
+
+// fakeNode emulates a node, every node assigns its own ID to the database API
+type fakeNode struct {
+	name       string
+	databaseID caller.APIID
+	chainID    string
+
+	mutex      sync.Mutex
+	headBlock  uint32
+	down       bool
+	logins     int
+	broadcasts int
+	callbacks  []string
+}
+
+func (n *fakeNode) Call(api caller.APIID, method string, args []interface{}, reply interface{}) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if n.down {
+		return errConnection
+	}
+
+	var result interface{}
+	switch {
+	case api == transport.LoginAPIID && method == "database":
+		result = n.databaseID
+	case api == transport.LoginAPIID && method == "login":
+		n.logins++
+		result = true
+	case api == n.databaseID && method == "get_chain_id":
+		result = n.chainID
+	case api == n.databaseID && method == "get_dynamic_global_properties":
+		result = map[string]interface{}{"head_block_number": n.headBlock}
+	case api == n.databaseID && method == "get_name":
+		result = n.name
+	case api == n.databaseID && method == "broadcast_transaction":
+		n.broadcasts++
+	case api == n.databaseID && method == "cancel_all_subscriptions":
+		n.callbacks = nil
+	default:
+		return &transport.RPCError{Code: 1, Message: "unknown method " + method}
+	}
+
+	raw, _ := json.Marshal(result)
+	if reply != nil {
+		return json.Unmarshal(raw, reply)
+	}
+	return nil
+}
+
+func (n *fakeNode) SetCallback(api caller.APIID, method string, callback func(raw json.RawMessage)) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.down {
+		return errConnection
+	}
+	n.callbacks = append(n.callbacks, method)
+	return nil
+}
+
+func (n *fakeNode) Close() error { return nil }
+
+func (n *fakeNode) set(down bool, headBlock uint32) {
+	n.mutex.Lock()
+	n.down = down
+	n.headBlock = headBlock
+	n.mutex.Unlock()
+}
+
+func (n *fakeNode) subscriptions() []string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.callbacks
+}
+
+func newNodes() (*fakeNode, *fakeNode) {
+	a := &fakeNode{name: "a", databaseID: 2, chainID: "chain", headBlock: 100}
+	b := &fakeNode{name: "b", databaseID: 5, chainID: "chain", headBlock: 100}
+	return a, b
+}
+
+func name(t *testing.T, p *Pool) string {
+	databaseID, err := login.NewAPI(p).Database()
+	require.NoError(t, err)
+
+	var name string
+	require.NoError(t, p.Call(databaseID, "get_name", caller.EmptyParams, &name))
+	return name
+}
+
+func TestPool_Failover(t *testing.T) {
+	a, b := newNodes()
+	p, err := New([]caller.CallCloser{a, b}, WithHealthCheckInterval(time.Hour))
+	require.NoError(t, err)
+	defer p.Close()
+
+	require.Equal(t, "chain", p.ChainID())
+	require.Equal(t, "a", name(t, p))
+
+	// the database API ID is translated for every node
+	a.set(true, 100)
+	require.Equal(t, "b", name(t, p))
+
+	// errors returned by the node are not retried
+	a.set(false, 100)
+	err = p.Call(transport.LoginAPIID, "unknown", caller.EmptyParams, nil)
+	require.IsType(t, &transport.RPCError{}, err)
+
+	// all nodes are down
+	a.set(true, 100)
+	b.set(true, 100)
+	require.Equal(t, errConnection, errors.Cause(p.Call(2, "get_name", caller.EmptyParams, nil)))
+}
+
+func TestPool_Failover_Broadcast(t *testing.T) {
+	a, b := newNodes()
+	p, err := New([]caller.CallCloser{a, b}, WithHealthCheckInterval(time.Hour))
+	require.NoError(t, err)
+	defer p.Close()
+
+	databaseID, err := login.NewAPI(p).Database()
+	require.NoError(t, err)
+	require.NoError(t, p.Call(databaseID, "broadcast_transaction", caller.EmptyParams, nil))
+	require.Equal(t, 1, a.broadcasts)
+
+	// the transaction might have been accepted by a, it is not sent to b
+	a.set(true, 100)
+	err = p.Call(databaseID, "broadcast_transaction", caller.EmptyParams, nil)
+	require.Equal(t, errConnection, errors.Cause(err))
+	require.Equal(t, 0, b.broadcasts)
+
+	// a is marked as failed, the next broadcast goes to b
+	require.NoError(t, p.Call(databaseID, "broadcast_transaction", caller.EmptyParams, nil))
+	require.Equal(t, 1, b.broadcasts)
+}
+
+func TestPool_UnverifiedNode(t *testing.T) {
+	a, b := newNodes()
+	a.set(true, 100)
+
+	p, err := New([]caller.CallCloser{a, b}, WithHealthCheckInterval(time.Hour))
+	require.NoError(t, err)
+	defer p.Close()
+	require.Equal(t, "b", name(t, p))
+
+	// a is up, but its chain ID has never been checked, so it gets no calls even as the last resort
+	a.set(false, 100)
+	b.set(true, 100)
+	require.Equal(t, errConnection, errors.Cause(p.Call(2, "get_name", caller.EmptyParams, nil)))
+}
+
+func TestPool_HeadBlockLag(t *testing.T) {
+	a, b := newNodes()
+	a.set(false, 10)
+	b.set(false, 100)
+
+	p, err := New([]caller.CallCloser{a, b}, WithHealthCheckInterval(10*time.Millisecond))
+	require.NoError(t, err)
+	defer p.Close()
+
+	require.Equal(t, "b", name(t, p))
+
+	// a has caught up and is preferred again
+	a.set(false, 100)
+	require.Eventually(t, func() bool {
+		return name(t, p) == "a"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPool_ChainIDMismatch(t *testing.T) {
+	a, b := newNodes()
+	b.chainID = "other"
+
+	_, err := New([]caller.CallCloser{a, b})
+	require.Error(t, err)
+	require.Equal(t, ErrChainIDMismatch, errors.Cause(err))
+
+	_, err = New([]caller.CallCloser{a}, WithChainID("other"))
+	require.Error(t, err)
+}
+
+func TestPool_NoHealthyNodes(t *testing.T) {
+	a, b := newNodes()
+	a.set(true, 0)
+	b.set(true, 0)
+
+	_, err := New([]caller.CallCloser{a, b})
+	require.Error(t, err)
+}
+
+func TestPool_Login(t *testing.T) {
+	a, b := newNodes()
+	p, err := New([]caller.CallCloser{a, b}, WithHealthCheckInterval(time.Hour))
+	require.NoError(t, err)
+	defer p.Close()
+
+	ok, err := login.NewAPI(p).Login("user", "password")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1, a.logins)
+
+	// a repeated login is replayed once
+	_, err = login.NewAPI(p).Login("user", "password")
+	require.NoError(t, err)
+	require.Len(t, p.handshake, 1)
+
+	// the login is replayed on the node taking over
+	a.set(true, 100)
+	require.Equal(t, "b", name(t, p))
+	require.Equal(t, 1, b.logins)
+}
+
+func TestPool_SetCallback(t *testing.T) {
+	a, b := newNodes()
+	p, err := New([]caller.CallCloser{a, b}, WithHealthCheckInterval(10*time.Millisecond))
+	require.NoError(t, err)
+	defer p.Close()
+
+	databaseID, err := login.NewAPI(p).Database()
+	require.NoError(t, err)
+
+	require.NoError(t, database.NewAPI(databaseID, p).SetBlockAppliedCallback(func(string, error) {}))
+	require.Equal(t, []string{"set_block_applied_callback"}, a.subscriptions())
+
+	// the callbacks are moved to b once a fails
+	a.set(true, 100)
+	require.Eventually(t, func() bool {
+		return len(b.subscriptions()) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+// warnings records the warnings
+type warnings struct {
+	logging.Logger
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (w *warnings) Warn(msg string, keyvals ...interface{}) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.messages = append(w.messages, msg)
+}
+
+func TestDial(t *testing.T) {
+	server := nodetest.NewServer()
+	defer server.Close()
+	server.Respond("database", "get_chain_id", "chain")
+	server.RespondJSON("database", "get_dynamic_global_properties", `{"head_block_number": 100}`)
+
+	logger := &warnings{Logger: logging.Nop}
+	p, err := Dial([]string{"ws://127.0.0.1:1", server.URL}, WithLogger(logger), WithHealthCheckInterval(time.Hour))
+	require.NoError(t, err)
+	defer p.Close()
+	require.Equal(t, "chain", p.ChainID())
+	require.Equal(t, []string{"node is unreachable"}, logger.messages)
+
+	_, err = Dial([]string{"ws://127.0.0.1:1"})
+	require.Equal(t, ErrNoNodes, err)
+}
+
+// count returns the number of the calls of the method
+func count(calls []nodetest.Call, method string) int {
+	n := 0
+	for _, call := range calls {
+		if call.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+func TestPool_SetCallback_Reconnect(t *testing.T) {
+	var servers []*nodetest.Server
+	var nodes []caller.CallCloser
+	for i := 0; i < 2; i++ {
+		server := nodetest.NewServer()
+		defer server.Close()
+		server.Respond("database", "get_chain_id", "chain")
+		server.RespondJSON("database", "get_dynamic_global_properties", `{"head_block_number": 100}`)
+		server.Respond("database", "cancel_all_subscriptions", nil)
+		servers = append(servers, server)
+
+		tr, err := websocket.NewTransport(server.URL)
+		require.NoError(t, err)
+		nodes = append(nodes, tr)
+	}
+	a, b := servers[0], servers[1]
+
+	// the session of a is not restored until the gate is open,
+	// so the pool moves the callbacks while a is reconnecting
+	var gateMutex sync.Mutex
+	var gate chan struct{}
+	a.Handle(nodetest.LoginAPI, "database", func([]json.RawMessage) (interface{}, error) {
+		gateMutex.Lock()
+		wait := gate
+		gateMutex.Unlock()
+		if wait != nil {
+			<-wait
+		}
+		return 2, nil
+	})
+
+	p, err := New(nodes, WithHealthCheckInterval(10*time.Millisecond))
+	require.NoError(t, err)
+	defer p.Close()
+
+	databaseID, err := login.NewAPI(p).Database()
+	require.NoError(t, err)
+
+	var mutex sync.Mutex
+	var blocks []string
+	require.NoError(t, database.NewAPI(databaseID, p).SetBlockAppliedCallback(func(blockID string, err error) {
+		mutex.Lock()
+		blocks = append(blocks, blockID)
+		mutex.Unlock()
+	}))
+
+	// the callbacks are moved to b while a is reconnecting
+	gateMutex.Lock()
+	gate = make(chan struct{})
+	gateMutex.Unlock()
+	a.Drop()
+	lost := len(a.Calls())
+	require.Eventually(t, func() bool {
+		return count(b.Calls(), "set_block_applied_callback") == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	close(gate)
+
+	// a is back once the health check gets through after the session is restored
+	require.Eventually(t, func() bool {
+		calls := a.Calls()[lost:]
+		return count(calls, "database") > 0 && calls[len(calls)-1].Method == "get_dynamic_global_properties"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the callback is not registered on a again, so the notices are not duplicated
+	require.Equal(t, 1, count(a.Calls(), "set_block_applied_callback"))
+	require.NoError(t, a.Notify("set_block_applied_callback", []string{"a"}))
+	require.NoError(t, b.Notify("set_block_applied_callback", []string{"b"}))
+	require.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(blocks) > 0
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	require.Equal(t, []string{"b"}, blocks)
+}
@@ -8,6 +8,10 @@ import (
 
 var ErrShutdown = errors.New("connection is shut down")
 
+// ErrConnectionLost is matched with errors.Is by the errors of the calls failed because the connection
+// to the node has been lost, e.g. websocket.ErrReconnecting. Such a call might succeed on another attempt.
+var ErrConnectionLost = errors.New("connection is lost")
+
 type (
 	RPCRequest struct {
 		Method string      `json:"method"`
@@ -21,27 +25,36 @@ type (
 		ID     uint64           `json:"id"`
 	}
 
+	// RPCError is an error returned by the node.
+	// Graphene exceptions carry the details in Data, see Kind and Details.
 	RPCError struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
-		Data    struct {
-			Code    int    `json:"code"`
-			Name    string `json:"name"`
-			Message string `json:"message"`
-			Stack   []struct {
-				Context struct {
-					Level      string `json:"level"`
-					File       string `json:"file"`
-					Line       int    `json:"line"`
-					Method     string `json:"method"`
-					Hostname   string `json:"hostname"`
-					ThreadName string `json:"thread_name"`
-					Timestamp  string `json:"timestamp"`
-				} `json:"context"`
-				Format string      `json:"format"`
-				Data   interface{} `json:"data"`
-			} `json:"stack"`
-		} `json:"data"`
+		Code    int          `json:"code"`
+		Message string       `json:"message"`
+		Data    RPCErrorData `json:"data"`
+	}
+
+	// RPCErrorData is the Graphene (fc) exception
+	RPCErrorData struct {
+		Code    int             `json:"code"`
+		Name    string          `json:"name"`
+		Message string          `json:"message"`
+		Stack   []RPCErrorFrame `json:"stack"`
+	}
+
+	// RPCErrorFrame is a single frame of the exception stack.
+	// Format is the assertion message with ${name} placeholders substituted from Data.
+	RPCErrorFrame struct {
+		Context struct {
+			Level      string `json:"level"`
+			File       string `json:"file"`
+			Line       int    `json:"line"`
+			Method     string `json:"method"`
+			Hostname   string `json:"hostname"`
+			ThreadName string `json:"thread_name"`
+			Timestamp  string `json:"timestamp"`
+		} `json:"context"`
+		Format string                 `json:"format"`
+		Data   map[string]interface{} `json:"data"`
 	}
 
 	RPCIncoming struct {
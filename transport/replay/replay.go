@@ -0,0 +1,245 @@
+// Package replay records the calls made through a caller.CallCloser and serves them back.
+// A session captured once against a real node with the Recorder becomes a deterministic
+// fixture served by the Replayer. The recordings are stored as JSON lines, one Entry per call.
+// Notices are not recorded, the Replayer never invokes the callbacks.
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/transport"
+)
+
+// ErrNotRecorded is returned by the Replayer when the call is not found in the recording
+var ErrNotRecorded = errors.New("call is not recorded")
+
+// Entry is a single recorded call
+type Entry struct {
+	API    caller.APIID    `json:"api"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// Error is set if the node responded with an error
+	Error *transport.RPCError `json:"error,omitempty"`
+
+	// Failure is set if the call failed for any other reason, e.g. a network error
+	Failure string `json:"failure,omitempty"`
+}
+
+// Recorder is a caller.CallCloser decorator writing every call to the underlying writer
+type Recorder struct {
+	next    caller.CallCloser
+	encoder *json.Encoder
+	closer  io.Closer
+
+	mutex sync.Mutex
+}
+
+// NewRecorder creates a Recorder passing the calls to next and writing them to w
+func NewRecorder(next caller.CallCloser, w io.Writer) *Recorder {
+	return &Recorder{next: next, encoder: json.NewEncoder(w)}
+}
+
+// Record creates a Recorder writing to the file with the given name.
+// The file is truncated, it is closed together with the Recorder.
+func Record(next caller.CallCloser, filename string) (*Recorder, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the recording")
+	}
+
+	r := NewRecorder(next, f)
+	r.closer = f
+	return r, nil
+}
+
+func (r *Recorder) Call(api caller.APIID, method string, args []interface{}, reply interface{}) error {
+	return r.CallContext(context.Background(), api, method, args, reply)
+}
+
+// CallContext implements caller.ContextCaller
+func (r *Recorder) CallContext(ctx context.Context, api caller.APIID, method string, args []interface{}, reply interface{}) error {
+	params, err := json.Marshal(args)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal params")
+	}
+
+	var result json.RawMessage
+	callErr := caller.CallContext(ctx, r.next, api, method, args, &result)
+
+	entry := Entry{API: api, Method: method, Params: params, Result: result}
+	if callErr != nil {
+		if rpcErr, ok := errors.Cause(callErr).(*transport.RPCError); ok {
+			entry.Error = rpcErr
+		} else {
+			entry.Failure = callErr.Error()
+		}
+	}
+
+	r.mutex.Lock()
+	err = r.encoder.Encode(entry)
+	r.mutex.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "failed to write the recording")
+	}
+
+	if callErr != nil {
+		return callErr
+	}
+
+	if reply != nil && result != nil {
+		return json.Unmarshal(result, reply)
+	}
+	return nil
+}
+
+// SetCallback registers the callback on the underlying caller, the notices are not recorded
+func (r *Recorder) SetCallback(api caller.APIID, method string, callback func(raw json.RawMessage)) error {
+	return r.next.SetCallback(api, method, callback)
+}
+
+// Close closes the underlying caller and the recording file if the Recorder has created it
+func (r *Recorder) Close() error {
+	err := r.next.Close()
+	if r.closer != nil {
+		if cerr := r.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Replayer is a caller.CallCloser serving the recorded calls.
+// A call is matched by the API ID, the method and the params. Every entry is served once,
+// so the same call made several times gets the recorded responses in the original order.
+type Replayer struct {
+	entries []*Entry
+	used    []bool
+	closing bool
+
+	mutex sync.Mutex
+}
+
+// NewReplayer reads the recording from r
+func NewReplayer(r io.Reader) (*Replayer, error) {
+	replayer := &Replayer{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, errors.Wrapf(err, "invalid entry %s", scanner.Bytes())
+		}
+		replayer.entries = append(replayer.entries, &entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read the recording")
+	}
+
+	replayer.used = make([]bool, len(replayer.entries))
+	return replayer, nil
+}
+
+// Replay creates a Replayer reading the recording from the file with the given name
+func Replay(filename string) (*Replayer, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open the recording")
+	}
+	defer f.Close()
+
+	return NewReplayer(f)
+}
+
+func (r *Replayer) Call(api caller.APIID, method string, args []interface{}, reply interface{}) error {
+	params, err := json.Marshal(args)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal params")
+	}
+
+	r.mutex.Lock()
+	if r.closing {
+		r.mutex.Unlock()
+		return transport.ErrShutdown
+	}
+
+	var entry *Entry
+	for i, e := range r.entries {
+		if !r.used[i] && e.API == api && e.Method == method && equalJSON(e.Params, params) {
+			r.used[i] = true
+			entry = e
+			break
+		}
+	}
+	r.mutex.Unlock()
+
+	if entry == nil {
+		return errors.Wrapf(ErrNotRecorded, "%d %s %s", api, method, params)
+	}
+
+	switch {
+	case entry.Error != nil:
+		return entry.Error
+	case entry.Failure != "":
+		return errors.New(entry.Failure)
+	}
+
+	if reply != nil && entry.Result != nil {
+		return json.Unmarshal(entry.Result, reply)
+	}
+	return nil
+}
+
+// SetCallback accepts the callback which is never invoked
+func (r *Replayer) SetCallback(api caller.APIID, method string, callback func(raw json.RawMessage)) error {
+	return nil
+}
+
+// Close makes every subsequent call fail with ErrShutdown
+func (r *Replayer) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closing {
+		return transport.ErrShutdown
+	}
+	r.closing = true
+	return nil
+}
+
+// Unused returns the recorded entries which have not been replayed yet
+func (r *Replayer) Unused() []Entry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var unused []Entry
+	for i, e := range r.entries {
+		if !r.used[i] {
+			unused = append(unused, *e)
+		}
+	}
+	return unused
+}
+
+// equalJSON compares two JSON documents ignoring the formatting
+func equalJSON(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	ra, _ := json.Marshal(va)
+	rb, _ := json.Marshal(vb)
+	return string(ra) == string(rb)
+}
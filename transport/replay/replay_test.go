@@ -0,0 +1,64 @@
+package replay
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/scorum/bitshares-go/apis/database"
+	"github.com/scorum/bitshares-go/apis/login"
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/nodetest"
+	"github.com/scorum/bitshares-go/transport/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+const chainID = "4018d7844c78f6a6c41c6a552b898022310fc5dec06da467ee7905a8dad512c8"
+
+func session(t *testing.T, cc caller.CallCloser) {
+	databaseID, err := login.NewAPI(cc).Database()
+	require.NoError(t, err)
+	api := database.NewAPI(databaseID, cc)
+
+	for i := 0; i < 2; i++ {
+		id, err := api.GetChainID()
+		require.NoError(t, err)
+		require.Equal(t, chainID, *id)
+	}
+
+	symbols, err := api.LookupAssetSymbols("USD")
+	require.NoError(t, err)
+	require.Len(t, symbols, 1)
+	require.Equal(t, "1.3.121", symbols[0].ID.String())
+
+	_, err = api.GetNamedAccountBalances("nonexists")
+	require.Error(t, err)
+}
+
+func TestRecordReplay(t *testing.T) {
+	server := nodetest.NewServer()
+	defer server.Close()
+	server.Respond("database", "get_chain_id", chainID)
+	server.RespondJSON("database", "lookup_asset_symbols", `[{"id":"1.3.121","symbol":"USD","precision":4}]`)
+	server.RespondError("database", "get_named_account_balances", "Assert Exception: no such account")
+
+	tr, err := websocket.NewTransport(server.URL)
+	require.NoError(t, err)
+
+	var recording bytes.Buffer
+	recorder := NewRecorder(tr, &recording)
+	session(t, recorder)
+	require.NoError(t, recorder.Close())
+
+	replayer, err := NewReplayer(&recording)
+	require.NoError(t, err)
+	session(t, replayer)
+	require.Empty(t, replayer.Unused())
+
+	t.Run("not recorded", func(t *testing.T) {
+		err := replayer.Call(2, "get_chain_id", caller.EmptyParams, nil)
+		require.Error(t, err)
+
+		_, err = database.NewAPI(2, replayer).LookupAssetSymbols("EUR")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,47 @@
+package websocket
+
+import (
+	"math"
+	"time"
+)
+
+// Backoff describes the delays between reconnection attempts.
+// The delay starts at Min and is multiplied by Factor after every failed attempt, up to Max.
+type Backoff struct {
+	Min    time.Duration
+	Max    time.Duration
+	Factor float64
+
+	// MaxAttempts limits the number of reconnection attempts, 0 means unlimited
+	MaxAttempts int
+}
+
+// DefaultBackoff retries forever starting with 1 second delay up to 1 minute
+var DefaultBackoff = Backoff{
+	Min:    time.Second,
+	Max:    time.Minute,
+	Factor: 2,
+}
+
+// Delay returns the delay before the given (zero based) attempt
+func (b Backoff) Delay(attempt int) time.Duration {
+	if attempt == 0 {
+		return 0
+	}
+
+	factor := b.Factor
+	if factor < 1 {
+		factor = 1
+	}
+
+	delay := float64(b.Min) * math.Pow(factor, float64(attempt-1))
+	if b.Max > 0 && delay > float64(b.Max) {
+		return b.Max
+	}
+	return time.Duration(delay)
+}
+
+// Retry reports whether the given (zero based) attempt is allowed
+func (b Backoff) Retry(attempt int) bool {
+	return b.MaxAttempts == 0 || attempt < b.MaxAttempts
+}
@@ -0,0 +1,26 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Min: time.Second, Max: 5 * time.Second, Factor: 2}
+
+	require.Equal(t, time.Duration(0), b.Delay(0))
+	require.Equal(t, time.Second, b.Delay(1))
+	require.Equal(t, 2*time.Second, b.Delay(2))
+	require.Equal(t, 4*time.Second, b.Delay(3))
+	require.Equal(t, 5*time.Second, b.Delay(4))
+}
+
+func TestBackoff_Retry(t *testing.T) {
+	require.True(t, Backoff{}.Retry(100))
+
+	b := Backoff{MaxAttempts: 2}
+	require.True(t, b.Retry(1))
+	require.False(t, b.Retry(2))
+}
@@ -0,0 +1,134 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/transport"
+	"golang.org/x/net/websocket"
+)
+
+// BatchCall implements caller.BatchCaller. All of the requests are written at once
+// and the responses are awaited together, so the batch takes a single round trip.
+func (caller *Transport) BatchCall(ctx context.Context, requests []*caller.BatchRequest) error {
+	if err := caller.batchCall(ctx, requests); err != nil {
+		for _, r := range requests {
+			if r.Err == nil {
+				r.Err = err
+			}
+		}
+		return err
+	}
+
+	for _, r := range requests {
+		if r.Err != nil {
+			return r.Err
+		}
+	}
+	return nil
+}
+
+func (caller *Transport) batchCall(ctx context.Context, requests []*caller.BatchRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	labels := make([]string, len(requests))
+	for i, r := range requests {
+		labels[i] = caller.apiLabel(r.API)
+	}
+
+	caller.mutex.Lock()
+	switch {
+	case caller.closing || caller.shutdown:
+		caller.mutex.Unlock()
+		return transport.ErrShutdown
+	case caller.reconnecting || caller.broken:
+		caller.mutex.Unlock()
+		return ErrReconnecting
+	}
+
+	conn := caller.conn
+	seqs := make([]uint64, len(requests))
+	calls := make([]*callRequest, len(requests))
+	messages := make([]transport.RPCRequest, len(requests))
+	for i, r := range requests {
+		api, ok := caller.apiIDs[r.API]
+		if !ok {
+			api = r.API
+		}
+		seqs[i], calls[i] = caller.register()
+		messages[i] = transport.RPCRequest{
+			Method: "call",
+			ID:     seqs[i],
+			Params: []interface{}{api, r.Method, r.Args},
+		}
+	}
+	caller.mutex.Unlock()
+
+	// the responses to the requests sent before a failure are discarded
+	discard := func() {
+		caller.mutex.Lock()
+		for _, seq := range seqs {
+			delete(caller.pending, seq)
+		}
+		caller.mutex.Unlock()
+	}
+
+	// finish reports the calls from the i-th on as completed with the error
+	start := time.Now()
+	for i, r := range requests {
+		caller.metrics.CallStarted(labels[i], r.Method)
+	}
+	finish := func(from int, err error) {
+		for i := from; i < len(requests); i++ {
+			caller.metrics.CallFinished(labels[i], requests[i].Method, time.Since(start), err)
+		}
+	}
+
+	var err error
+	caller.sendMutex.Lock()
+	for _, message := range messages {
+		if err = websocket.JSON.Send(conn, message); err != nil {
+			break
+		}
+	}
+	caller.sendMutex.Unlock()
+	if err != nil {
+		discard()
+		finish(0, err)
+		// the frame might have been written partially, the connection is unusable
+		conn.Close()
+		return err
+	}
+
+	for i, c := range calls {
+		select {
+		case <-c.Done:
+		case <-ctx.Done():
+			discard()
+			finish(i, ctx.Err())
+			return ctx.Err()
+		}
+
+		r := requests[i]
+		caller.metrics.CallFinished(labels[i], r.Method, time.Since(start), c.Error)
+		if c.Error != nil {
+			r.Err = c.Error
+			continue
+		}
+
+		var raw json.RawMessage
+		if c.Reply != nil {
+			raw = *c.Reply
+		}
+		caller.remember(r.API, r.Method, r.Args, raw)
+
+		if r.Reply != nil && raw != nil {
+			r.Err = json.Unmarshal(raw, r.Reply)
+		}
+	}
+	return nil
+}
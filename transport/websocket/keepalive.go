@@ -0,0 +1,206 @@
+package websocket
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/transport"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// DefaultKeepalive is the default interval between the pings
+	DefaultKeepalive = 30 * time.Second
+
+	// DefaultReadTimeout is the default time the node may stay silent, including the pongs
+	DefaultReadTimeout = 90 * time.Second
+
+	// DefaultWriteTimeout is the default time a single write may take
+	DefaultWriteTimeout = 10 * time.Second
+
+	// minWatchInterval is the shortest interval between the notice timeout checks
+	minWatchInterval = time.Millisecond
+)
+
+var (
+	// ErrTimeout is returned by the calls pending when a read or a write has timed out,
+	// it matches transport.ErrConnectionLost
+	ErrTimeout = errors.WithMessage(transport.ErrConnectionLost, "connection timed out")
+
+	// ErrNoticeTimeout is returned by the calls pending when the node has stopped sending notices,
+	// it matches transport.ErrConnectionLost
+	ErrNoticeTimeout = errors.WithMessage(transport.ErrConnectionLost, "no notices received in time")
+)
+
+// WithKeepalive sets the interval between the pings, 0 disables the pings.
+// Every pong refreshes the read deadline, so an idle but healthy connection is never dropped.
+func WithKeepalive(interval time.Duration) Option {
+	return func(t *Transport) {
+		t.pingInterval = interval
+	}
+}
+
+// WithReadTimeout sets the time the node may stay silent before the connection is considered lost,
+// 0 disables the read deadline. It bounds the dial and the websocket handshake as well.
+func WithReadTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.readTimeout = timeout
+	}
+}
+
+// WithWriteTimeout sets the time a single write may take, 0 disables the write deadline
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.writeTimeout = timeout
+	}
+}
+
+// WithNoticeTimeout makes the transport drop the connection if a callback is registered
+// and no notice has been received for the given time, e.g. the node has stopped producing blocks.
+// The timeout is checked every quarter of it, but not more often than every millisecond.
+// The notice timeout is disabled by default.
+func WithNoticeTimeout(timeout time.Duration) Option {
+	return func(t *Transport) {
+		t.noticeTimeout = timeout
+	}
+}
+
+// deadlineConn refreshes the deadline before every read and write
+type deadlineConn struct {
+	net.Conn
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+func (c *deadlineConn) Read(b []byte) (int, error) {
+	if c.readTimeout > 0 {
+		if err := c.Conn.SetReadDeadline(time.Now().Add(c.readTimeout)); err != nil {
+			return 0, err
+		}
+	}
+	n, err := c.Conn.Read(b)
+	return n, timeout(err)
+}
+
+func (c *deadlineConn) Write(b []byte) (int, error) {
+	if c.writeTimeout > 0 {
+		if err := c.Conn.SetWriteDeadline(time.Now().Add(c.writeTimeout)); err != nil {
+			return 0, err
+		}
+	}
+	n, err := c.Conn.Write(b)
+	return n, timeout(err)
+}
+
+// timeout replaces the net timeout errors with ErrTimeout
+func timeout(err error) error {
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return ErrTimeout
+	}
+	return err
+}
+
+// dial opens a websocket connection on top of a deadlineConn
+func (caller *Transport) dial() (*websocket.Conn, error) {
+	config, err := websocket.NewConfig(caller.url, "http://localhost")
+	if err != nil {
+		return nil, err
+	}
+
+	host := config.Location.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		port := "80"
+		if config.Location.Scheme == "wss" {
+			port = "443"
+		}
+		host = net.JoinHostPort(host, port)
+	}
+
+	dialer := &net.Dialer{Timeout: caller.readTimeout}
+	var conn net.Conn
+	switch config.Location.Scheme {
+	case "ws":
+		conn, err = dialer.Dial("tcp", host)
+	case "wss":
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, nil)
+	default:
+		err = websocket.ErrBadScheme
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to dial %s", caller.url)
+	}
+
+	ws, err := websocket.NewClient(config, &deadlineConn{
+		Conn:         conn,
+		readTimeout:  caller.readTimeout,
+		writeTimeout: caller.writeTimeout,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrapf(err, "websocket handshake with %s failed", caller.url)
+	}
+	return ws, nil
+}
+
+// active reports whether the connection is still in use
+func (caller *Transport) active(conn *websocket.Conn) bool {
+	caller.mutex.Lock()
+	defer caller.mutex.Unlock()
+	return conn == caller.conn && !caller.broken && !caller.closing
+}
+
+// keepalive pings the node until the connection is replaced or lost
+func (caller *Transport) keepalive(conn *websocket.Conn) {
+	ticker := time.NewTicker(caller.pingInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !caller.active(conn) {
+			return
+		}
+
+		// the pong is consumed by the websocket reader, it only refreshes the read deadline
+		caller.sendMutex.Lock()
+		conn.PayloadType = websocket.PingFrame
+		_, err := conn.Write(nil)
+		conn.PayloadType = websocket.TextFrame
+		caller.sendMutex.Unlock()
+
+		if err != nil {
+			caller.stop(conn, pingError(err))
+			return
+		}
+	}
+}
+
+// pingError is the error of the calls pending when the ping has failed, it matches transport.ErrConnectionLost
+func pingError(err error) error {
+	return errors.WithMessagef(transport.ErrConnectionLost, "ping failed: %v", err)
+}
+
+// watch drops the connection once the notices stop coming
+func (caller *Transport) watch(conn *websocket.Conn) {
+	interval := caller.noticeTimeout / 4
+	if interval < minWatchInterval {
+		interval = minWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !caller.active(conn) {
+			return
+		}
+
+		caller.mutex.Lock()
+		stale := len(caller.subscriptions) > 0 && time.Since(caller.lastNotice) > caller.noticeTimeout
+		caller.mutex.Unlock()
+
+		if stale {
+			caller.stop(conn, ErrNoticeTimeout)
+			return
+		}
+	}
+}
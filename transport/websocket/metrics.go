@@ -0,0 +1,51 @@
+package websocket
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/metrics"
+	"github.com/scorum/bitshares-go/transport"
+)
+
+// WithMetrics sets the collector of the call, reconnect and notice metrics
+func WithMetrics(collector metrics.Collector) Option {
+	return func(t *Transport) {
+		t.metrics = collector
+	}
+}
+
+// learnAPIName remembers the name of the API requested by the login_api method,
+// so the metrics are labeled by the API name. The mutex has to be held.
+func (caller *Transport) learnAPIName(method string, args []interface{}, reply json.RawMessage) {
+	name, ok := transport.APIName(method, args)
+
+	var id uint8
+	if !ok || name == "" || json.Unmarshal(reply, &id) != nil || id == 0 {
+		return
+	}
+	caller.apiNames.set(id, name)
+}
+
+// apiLabel returns the name of the API if it is known or the API ID otherwise
+func (caller *Transport) apiLabel(api caller.APIID) string {
+	if api == transport.LoginAPIID {
+		return "login"
+	}
+
+	caller.mutex.Lock()
+	name, ok := caller.apiNames[api]
+	caller.mutex.Unlock()
+	if ok {
+		return name
+	}
+	return strconv.Itoa(int(api))
+}
+
+// apiNameMapping maps the API IDs known to the user to the API names
+type apiNameMapping map[caller.APIID]string
+
+func (m apiNameMapping) set(id uint8, name string) {
+	m[caller.APIID(id)] = name
+}
@@ -1,32 +1,60 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/scorum/bitshares-go/caller"
-	"log"
 	"math"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
-	"github.com/scorum/scorum-go/transport"
+	"github.com/scorum/bitshares-go/logging"
+	"github.com/scorum/bitshares-go/metrics"
+	"github.com/scorum/bitshares-go/transport"
 	"golang.org/x/net/websocket"
 )
 
+// ErrReconnecting is returned by calls issued while the transport is restoring the connection,
+// it matches transport.ErrConnectionLost
+var ErrReconnecting = errors.WithMessage(transport.ErrConnectionLost, "reconnecting")
+
 type Transport struct {
+	url     string
+	backoff *Backoff
+	logger  logging.Logger
+	metrics metrics.Collector
+
+	pingInterval  time.Duration
+	readTimeout   time.Duration
+	writeTimeout  time.Duration
+	noticeTimeout time.Duration
+	lastNotice    time.Time
+
 	conn *websocket.Conn
 
-	reqMutex  sync.Mutex
+	sendMutex sync.Mutex
 	requestID uint64
 	pending   map[uint64]*callRequest
 
 	callbackMutex sync.Mutex
 	callbackID    uint64
 	callbacks     map[uint64]func(args json.RawMessage)
+	notices       map[uint64]string // callback ID to the method registered it
+	dropped       uint64            // the callbacks up to this ID have been dropped by ResetCallbacks
 
-	closing  bool // user has called Close
-	shutdown bool // server has told us to stop
+	// state required to restore the session after a reconnect
+	handshake     []*handshakeCall
+	apiIDs        idMapping
+	apiNames      apiNameMapping
+	subscriptions map[uint64]subscription
+
+	closing      bool // user has called Close
+	shutdown     bool // server has told us to stop
+	reconnecting bool // connection is being restored
+	broken       bool // current connection is lost
 
 	mutex sync.Mutex
 }
@@ -38,129 +66,443 @@ type callRequest struct {
 	Reply *json.RawMessage // reply message
 }
 
-func NewTransport(url string) (*Transport, error) {
-	ws, err := websocket.Dial(url, "", "http://localhost")
-	if err != nil {
-		return nil, err
+// handshakeCall is a login_api call (login, API ID requests) replayed on reconnect
+type handshakeCall struct {
+	method string
+	args   []interface{}
+	reply  json.RawMessage
+}
+
+// idMapping maps the API IDs known to the user to the IDs assigned on the current connection
+type idMapping map[caller.APIID]caller.APIID
+
+func (m idMapping) set(oldID, newID uint8) {
+	m[caller.APIID(oldID)] = caller.APIID(newID)
+}
+
+// subscription is a callback registered through SetCallback
+type subscription struct {
+	api    caller.APIID
+	method string
+}
+
+// Option configures the Transport
+type Option func(*Transport)
+
+// WithBackoff sets the delays used between reconnection attempts
+func WithBackoff(backoff Backoff) Option {
+	return func(t *Transport) {
+		t.backoff = &backoff
 	}
+}
 
+// WithLogger sets the logger, the transport is silent by default
+func WithLogger(logger logging.Logger) Option {
+	return func(t *Transport) {
+		t.logger = logger
+	}
+}
+
+// WithoutReconnect disables reconnection, the transport is shut down once the connection is lost
+func WithoutReconnect() Option {
+	return func(t *Transport) {
+		t.backoff = nil
+	}
+}
+
+func NewTransport(url string, options ...Option) (*Transport, error) {
 	client := &Transport{
-		conn:      ws,
-		pending:   make(map[uint64]*callRequest),
-		callbacks: make(map[uint64]func(args json.RawMessage)),
+		url:           url,
+		backoff:       &DefaultBackoff,
+		logger:        logging.Nop,
+		metrics:       metrics.Nop,
+		pingInterval:  DefaultKeepalive,
+		readTimeout:   DefaultReadTimeout,
+		writeTimeout:  DefaultWriteTimeout,
+		pending:       make(map[uint64]*callRequest),
+		callbacks:     make(map[uint64]func(args json.RawMessage)),
+		notices:       make(map[uint64]string),
+		apiIDs:        make(idMapping),
+		apiNames:      make(apiNameMapping),
+		subscriptions: make(map[uint64]subscription),
+	}
+
+	for _, option := range options {
+		option(client)
+	}
+
+	ws, err := client.dial()
+	if err != nil {
+		return nil, err
 	}
+	client.conn = ws
+	client.lastNotice = time.Now()
 
-	go client.input()
+	client.start(ws)
 	return client, nil
 }
 
+// start serves the connection
+func (caller *Transport) start(conn *websocket.Conn) {
+	go caller.input(conn)
+	if caller.pingInterval > 0 {
+		go caller.keepalive(conn)
+	}
+	if caller.noticeTimeout > 0 {
+		go caller.watch(conn)
+	}
+}
+
 func (caller *Transport) Call(api caller.APIID, method string, args []interface{}, reply interface{}) error {
-	caller.reqMutex.Lock()
-	defer caller.reqMutex.Unlock()
+	return caller.CallContext(context.Background(), api, method, args, reply)
+}
 
+// CallContext implements caller.ContextCaller. Once the context is done the call
+// returns ctx.Err() and the response, if it ever comes, is discarded.
+func (caller *Transport) CallContext(ctx context.Context, api caller.APIID, method string, args []interface{}, reply interface{}) error {
 	caller.mutex.Lock()
 	if caller.closing || caller.shutdown {
 		caller.mutex.Unlock()
 		return transport.ErrShutdown
 	}
+	if caller.reconnecting {
+		caller.mutex.Unlock()
+		return ErrReconnecting
+	}
+	conn := caller.conn
+	mapped, ok := caller.apiIDs[api]
+	caller.mutex.Unlock()
 
-	// increase request id
-	if caller.requestID == math.MaxUint64 {
-		caller.requestID = 0
+	if !ok {
+		mapped = api
 	}
-	caller.requestID++
-	seq := caller.requestID
 
-	c := &callRequest{
-		Done: make(chan bool, 1),
+	label := caller.apiLabel(api)
+	caller.metrics.CallStarted(label, method)
+	start := time.Now()
+
+	var raw json.RawMessage
+	err := caller.call(ctx, conn, mapped, method, args, &raw)
+	caller.metrics.CallFinished(label, method, time.Since(start), err)
+	if err != nil {
+		return err
 	}
-	caller.pending[seq] = c
+
+	caller.remember(api, method, args, raw)
+
+	if reply != nil && raw != nil {
+		if err := json.Unmarshal(raw, reply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// call sends the request over the given connection and waits for the raw reply.
+// Calls are not serialized: any number of requests might be in flight,
+// the responses are routed to the waiting callers by the request ID.
+func (caller *Transport) call(ctx context.Context, conn *websocket.Conn, api caller.APIID, method string, args []interface{}, reply *json.RawMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	caller.mutex.Lock()
+	// the connection might have been lost after the caller picked it up
+	if conn != caller.conn || caller.broken {
+		shutdown := caller.shutdown
+		caller.mutex.Unlock()
+		if shutdown {
+			return transport.ErrShutdown
+		}
+		return ErrReconnecting
+	}
+
+	seq, c := caller.register()
 	caller.mutex.Unlock()
 
 	request := transport.RPCRequest{
 		Method: "call",
-		ID:     caller.requestID,
+		ID:     seq,
 		Params: []interface{}{api, method, args},
 	}
 
 	// send Json Rcp request
-	if err := websocket.JSON.Send(caller.conn, request); err != nil {
+	caller.sendMutex.Lock()
+	err := websocket.JSON.Send(conn, request)
+	caller.sendMutex.Unlock()
+	if err != nil {
 		caller.mutex.Lock()
 		delete(caller.pending, seq)
 		caller.mutex.Unlock()
+		// the frame might have been written partially, the connection is unusable
+		conn.Close()
 		return err
 	}
 
 	// wait for the call to complete
-	<-c.Done
+	select {
+	case <-c.Done:
+	case <-ctx.Done():
+		caller.mutex.Lock()
+		delete(caller.pending, seq)
+		caller.mutex.Unlock()
+		return ctx.Err()
+	}
+
 	if c.Error != nil {
 		return c.Error
 	}
 
 	if c.Reply != nil {
-		if err := json.Unmarshal(*c.Reply, reply); err != nil {
-			return err
-		}
+		*reply = *c.Reply
 	}
 	return nil
 }
 
-func (caller *Transport) input() {
+// register adds a new pending call, the mutex has to be held
+func (caller *Transport) register() (uint64, *callRequest) {
+	// increase request id
+	if caller.requestID == math.MaxUint64 {
+		caller.requestID = 0
+	}
+	caller.requestID++
+	seq := caller.requestID
+
+	c := &callRequest{
+		Done: make(chan bool, 1),
+	}
+	caller.pending[seq] = c
+	return seq, c
+}
+
+// remember keeps track of the session state which has to be restored after a reconnect
+func (caller *Transport) remember(api caller.APIID, method string, args []interface{}, reply json.RawMessage) {
+	caller.mutex.Lock()
+	defer caller.mutex.Unlock()
+
+	// cancel_all_subscriptions drops every callback on the node side,
+	// so there is nothing to re-register anymore
+	if method == "cancel_all_subscriptions" {
+		caller.subscriptions = make(map[uint64]subscription)
+		return
+	}
+
+	if api != transport.LoginAPIID {
+		return
+	}
+	caller.learnAPIName(method, args, reply)
+
+	for _, h := range caller.handshake {
+		if h.method == method && fmt.Sprint(h.args) == fmt.Sprint(args) {
+			return
+		}
+	}
+	caller.handshake = append(caller.handshake, &handshakeCall{method: method, args: args, reply: reply})
+}
+
+func (caller *Transport) input(conn *websocket.Conn) {
 	for {
 		var message string
-		if err := websocket.Message.Receive(caller.conn, &message); err != nil {
-			caller.stop(err)
+		if err := websocket.Message.Receive(conn, &message); err != nil {
+			caller.stop(conn, err)
 			return
 		}
 
 		var response transport.RPCResponse
 		if err := json.Unmarshal([]byte(message), &response); err != nil {
-			caller.stop(err)
+			caller.stop(conn, err)
 			return
 		} else {
-			if call, ok := caller.pending[response.ID]; ok {
+			caller.mutex.Lock()
+			call, ok := caller.pending[response.ID]
+			caller.mutex.Unlock()
+
+			if ok {
 				caller.onCallResponse(response, call)
 			} else {
 				//the message is not a pending call, but probably a callback notice
 				var incoming transport.RPCIncoming
 				if err := json.Unmarshal([]byte(message), &incoming); err != nil {
-					caller.stop(err)
+					caller.stop(conn, err)
 					return
 				}
 				if incoming.Method == "notice" {
 					if err := caller.onNotice(incoming); err != nil {
-						caller.stop(err)
+						caller.stop(conn, err)
 						return
 					}
 				} else {
-					log.Printf("protocol error: unknown message received: %+v\n", incoming)
+					caller.logger.Warn("protocol error: unknown message received", "url", caller.url, "message", message)
 				}
 			}
 		}
 	}
 }
 
-// Return pending clients and shutdown the client
-func (caller *Transport) stop(err error) {
-	caller.reqMutex.Lock()
-	caller.shutdown = true
-	for _, call := range caller.pending {
+// Return pending clients and either shutdown the client or start reconnecting
+func (caller *Transport) stop(conn *websocket.Conn, err error) {
+	caller.mutex.Lock()
+	// the connection has already been stopped, e.g. by the keepalive
+	if conn != caller.conn || caller.broken {
+		caller.mutex.Unlock()
+		return
+	}
+	caller.broken = true
+	caller.failPending(err)
+
+	// the session is being restored on this connection, the running reconnect loop takes care of it
+	if caller.reconnecting {
+		caller.mutex.Unlock()
+		conn.Close()
+		return
+	}
+
+	if caller.closing || caller.backoff == nil {
+		caller.shutdown = true
+		caller.mutex.Unlock()
+		conn.Close()
+		return
+	}
+
+	caller.reconnecting = true
+	caller.mutex.Unlock()
+
+	conn.Close()
+	caller.logger.Warn("connection is lost, reconnecting", "url", caller.url, "error", err)
+	go caller.reconnect()
+}
+
+// failPending completes the pending calls with the error, the mutex has to be held.
+// A call is completed only by the one who removes it from pending, so Done is never sent twice.
+func (caller *Transport) failPending(err error) {
+	for id, call := range caller.pending {
 		call.Error = err
 		call.Done <- true
+		delete(caller.pending, id)
+	}
+}
+
+// reconnect dials the node until it succeeds or the backoff gives up.
+// A connection which fails to restore the session counts as a failed attempt.
+func (caller *Transport) reconnect() {
+	for attempt := 0; ; attempt++ {
+		caller.mutex.Lock()
+		closing := caller.closing
+		caller.mutex.Unlock()
+		if closing {
+			return
+		}
+
+		if !caller.backoff.Retry(attempt) {
+			caller.logger.Error("failed to reconnect", "url", caller.url, "attempts", attempt)
+			caller.mutex.Lock()
+			caller.reconnecting = false
+			caller.shutdown = true
+			caller.mutex.Unlock()
+			return
+		}
+		time.Sleep(caller.backoff.Delay(attempt))
+
+		conn, err := caller.dial()
+		if err != nil {
+			caller.logger.Warn("failed to reconnect", "url", caller.url, "attempt", attempt, "error", err)
+			continue
+		}
+
+		caller.mutex.Lock()
+		if caller.closing {
+			caller.mutex.Unlock()
+			conn.Close()
+			return
+		}
+		caller.conn = conn
+		caller.broken = false
+		caller.lastNotice = time.Now()
+		caller.mutex.Unlock()
+
+		caller.start(conn)
+
+		if err := caller.restore(conn); err != nil {
+			caller.logger.Warn("failed to restore the session", "url", caller.url, "attempt", attempt, "error", err)
+			// the connection is marked as broken before closing it,
+			// so stop() called by input() doesn't start another reconnect loop
+			caller.mutex.Lock()
+			caller.broken = true
+			caller.failPending(err)
+			caller.mutex.Unlock()
+			conn.Close()
+			continue
+		}
+
+		caller.mutex.Lock()
+		caller.reconnecting = false
+		caller.mutex.Unlock()
+		caller.metrics.Reconnected()
+		return
 	}
-	caller.reqMutex.Unlock()
+}
+
+// restore replays the login handshake and re-registers the callbacks on a fresh connection
+func (caller *Transport) restore(conn *websocket.Conn) error {
+	caller.mutex.Lock()
+	handshake := append([]*handshakeCall{}, caller.handshake...)
+	caller.mutex.Unlock()
+
+	apiIDs := make(idMapping)
+	for _, h := range handshake {
+		var reply json.RawMessage
+		if err := caller.call(context.Background(), conn, transport.LoginAPIID, h.method, h.args, &reply); err != nil {
+			return errors.Wrapf(err, "failed to replay %s", h.method)
+		}
+
+		// the node might assign different IDs to the APIs on a new connection
+		var oldID, newID uint8
+		if json.Unmarshal(h.reply, &oldID) == nil && json.Unmarshal(reply, &newID) == nil {
+			apiIDs.set(oldID, newID)
+		} else if string(h.reply) != string(reply) {
+			return errors.Errorf("%s replied %s, expected %s", h.method, reply, h.reply)
+		}
+	}
+
+	// the callbacks might have been reset while the handshake was replayed
+	caller.mutex.Lock()
+	subscriptions := make(map[uint64]subscription, len(caller.subscriptions))
+	for id, s := range caller.subscriptions {
+		subscriptions[id] = s
+	}
+	caller.mutex.Unlock()
+
+	for id, s := range subscriptions {
+		api, ok := apiIDs[s.api]
+		if !ok {
+			api = s.api
+		}
+		var reply json.RawMessage
+		if err := caller.call(context.Background(), conn, api, s.method, []interface{}{id}, &reply); err != nil {
+			return errors.Wrapf(err, "failed to re-register %s", s.method)
+		}
+	}
+
+	caller.mutex.Lock()
+	caller.apiIDs = apiIDs
+	caller.mutex.Unlock()
+	return nil
 }
 
 // Call response handler
 func (caller *Transport) onCallResponse(response transport.RPCResponse, call *callRequest) {
 	caller.mutex.Lock()
+	defer caller.mutex.Unlock()
+	// the call might have been completed by stop() or abandoned by its caller since it was looked up
+	if caller.pending[response.ID] != call {
+		return
+	}
 	delete(caller.pending, response.ID)
 	if response.Error != nil {
 		call.Error = response.Error
 	}
 	call.Reply = response.Result
 	call.Done <- true
-	caller.mutex.Unlock()
 }
 
 // Incoming notice handler
@@ -181,12 +523,25 @@ func (caller *Transport) onNotice(incoming transport.RPCIncoming) error {
 			return errors.Wrapf(err, "failed to parse %s as callbackID in notice %+v", incoming.Params[i], incoming)
 		}
 
+		caller.callbackMutex.Lock()
 		notice := caller.callbacks[callbackID]
+		method := caller.notices[callbackID]
+		dropped := callbackID <= caller.dropped
+		caller.callbackMutex.Unlock()
 		if notice == nil {
+			// the node might not have heard of ResetCallbacks yet
+			if dropped {
+				continue
+			}
 			return fmt.Errorf("callback %d is not registered", callbackID)
 		}
 
+		caller.mutex.Lock()
+		caller.lastNotice = time.Now()
+		caller.mutex.Unlock()
+
 		// invoke callback
+		caller.metrics.NoticeReceived(method)
 		notice(incoming.Params[i+1])
 	}
 
@@ -200,10 +555,36 @@ func (caller *Transport) SetCallback(api caller.APIID, method string, notice fun
 		caller.callbackID = 0
 	}
 	caller.callbackID++
-	caller.callbacks[caller.callbackID] = notice
+	id := caller.callbackID
+	caller.callbacks[id] = notice
+	caller.notices[id] = method
 	caller.callbackMutex.Unlock()
 
-	return caller.Call(api, method, []interface{}{caller.callbackID}, nil)
+	if err := caller.Call(api, method, []interface{}{id}, nil); err != nil {
+		return err
+	}
+
+	caller.mutex.Lock()
+	if len(caller.subscriptions) == 0 {
+		caller.lastNotice = time.Now()
+	}
+	caller.subscriptions[id] = subscription{api: api, method: method}
+	caller.mutex.Unlock()
+	return nil
+}
+
+// ResetCallbacks implements caller.CallbackResetter. The callbacks are not registered again on reconnect
+// and the notices the node still sends to them are ignored.
+func (caller *Transport) ResetCallbacks() {
+	caller.callbackMutex.Lock()
+	caller.callbacks = make(map[uint64]func(args json.RawMessage))
+	caller.notices = make(map[uint64]string)
+	caller.dropped = caller.callbackID
+	caller.callbackMutex.Unlock()
+
+	caller.mutex.Lock()
+	caller.subscriptions = make(map[uint64]subscription)
+	caller.mutex.Unlock()
 }
 
 // Close calls the underlying web socket Close method. If the connection is already
@@ -215,6 +596,7 @@ func (caller *Transport) Close() error {
 		return transport.ErrShutdown
 	}
 	caller.closing = true
+	conn := caller.conn
 	caller.mutex.Unlock()
-	return caller.conn.Close()
+	return conn.Close()
 }
@@ -0,0 +1,377 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/caller"
+	"github.com/scorum/bitshares-go/caller/retry"
+	"github.com/scorum/bitshares-go/metrics"
+	"github.com/scorum/bitshares-go/transport"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// node is a tiny node emulation: it assigns a new database API ID on every connection
+// and sends a notice for every registered callback
+type node struct {
+	mutex       sync.Mutex
+	connections int
+	conns       []*websocket.Conn
+
+	// brokenRestore drops the reconnected sessions on the API ID requests
+	brokenRestore bool
+}
+
+func (n *node) handle(ws *websocket.Conn) {
+	n.mutex.Lock()
+	n.connections++
+	databaseID := 1 + n.connections
+	n.conns = append(n.conns, ws)
+	n.mutex.Unlock()
+
+	for {
+		var request struct {
+			ID     uint64            `json:"id"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := websocket.JSON.Receive(ws, &request); err != nil {
+			return
+		}
+
+		var api int
+		var method string
+		json.Unmarshal(request.Params[0], &api)
+		json.Unmarshal(request.Params[1], &method)
+
+		var result interface{}
+		switch method {
+		case "slow":
+			// respond out of order
+			go func(id uint64) {
+				time.Sleep(time.Second)
+				websocket.JSON.Send(ws, map[string]interface{}{"id": id, "result": "slow"})
+			}(request.ID)
+			continue
+		case "database":
+			n.mutex.Lock()
+			broken := n.brokenRestore && n.connections > 1
+			n.mutex.Unlock()
+			if broken {
+				ws.Close()
+				return
+			}
+			result = databaseID
+		case "get_api":
+			result = api
+		case "set_block_applied_callback":
+			var args []uint64
+			json.Unmarshal(request.Params[2], &args)
+			go websocket.JSON.Send(ws, map[string]interface{}{
+				"method": "notice",
+				"params": []interface{}{args[0], []string{"block"}},
+			})
+		}
+		websocket.JSON.Send(ws, map[string]interface{}{"id": request.ID, "result": result})
+	}
+}
+
+// drop closes the server side of every connection
+func (n *node) drop() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	for _, c := range n.conns {
+		c.Close()
+	}
+	n.conns = nil
+}
+
+func TestTransport_Reconnect(t *testing.T) {
+	n := &node{}
+	server := httptest.NewServer(websocket.Handler(n.handle))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	tr, err := NewTransport(url, WithBackoff(Backoff{Min: 10 * time.Millisecond, Max: 10 * time.Millisecond}))
+	require.NoError(t, err)
+	defer tr.Close()
+
+	var databaseID uint8
+	require.NoError(t, tr.Call(1, "database", []interface{}{}, &databaseID))
+	require.Equal(t, uint8(2), databaseID)
+
+	notices := make(chan json.RawMessage, 10)
+	require.NoError(t, tr.SetCallback(2, "set_block_applied_callback", func(raw json.RawMessage) {
+		notices <- raw
+	}))
+	<-notices
+
+	n.drop()
+
+	// the callback is registered again once the session is restored
+	select {
+	case <-notices:
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback has not been restored")
+	}
+
+	// the database API got ID 3 on the new connection, the caller keeps using 2
+	var api int
+	require.Eventually(t, func() bool {
+		return tr.Call(2, "get_api", []interface{}{}, &api) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 3, api)
+}
+
+func TestTransport_Reconnect_MaxAttempts(t *testing.T) {
+	n := &node{brokenRestore: true}
+	server := httptest.NewServer(websocket.Handler(n.handle))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	tr, err := NewTransport(url, WithBackoff(Backoff{Min: 10 * time.Millisecond, Max: 10 * time.Millisecond, MaxAttempts: 3}))
+	require.NoError(t, err)
+	defer tr.Close()
+
+	var databaseID uint8
+	require.NoError(t, tr.Call(1, "database", []interface{}{}, &databaseID))
+
+	n.drop()
+
+	// every reconnect fails to restore the session, the failures count against MaxAttempts
+	require.Eventually(t, func() bool {
+		return tr.Call(1, "database", []interface{}{}, &databaseID) == transport.ErrShutdown
+	}, 5*time.Second, 10*time.Millisecond)
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	require.Equal(t, 4, n.connections)
+}
+
+func TestTransport_WithoutReconnect(t *testing.T) {
+	n := &node{}
+	server := httptest.NewServer(websocket.Handler(n.handle))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	tr, err := NewTransport(url, WithoutReconnect())
+	require.NoError(t, err)
+
+	var databaseID uint8
+	require.NoError(t, tr.Call(1, "database", []interface{}{}, &databaseID))
+
+	n.drop()
+
+	require.Eventually(t, func() bool {
+		return tr.Call(1, "database", []interface{}{}, &databaseID) != nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTransport_ConcurrentCalls(t *testing.T) {
+	n := &node{}
+	server := httptest.NewServer(websocket.Handler(n.handle))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	tr, err := NewTransport(url)
+	require.NoError(t, err)
+	defer tr.Close()
+
+	slow := make(chan string, 1)
+	go func() {
+		var reply string
+		tr.Call(2, "slow", []interface{}{}, &reply)
+		slow <- reply
+	}()
+
+	// the fast calls are not blocked by the slow one in flight
+	for i := 0; i < 10; i++ {
+		var api int
+		require.NoError(t, tr.Call(5, "get_api", []interface{}{}, &api))
+		require.Equal(t, 5, api)
+	}
+	require.Len(t, slow, 0)
+
+	require.Equal(t, "slow", <-slow)
+}
+
+func TestTransport_CallContext(t *testing.T) {
+	n := &node{}
+	server := httptest.NewServer(websocket.Handler(n.handle))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	tr, err := NewTransport(url)
+	require.NoError(t, err)
+	defer tr.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var reply string
+	err = tr.CallContext(ctx, 2, "slow", []interface{}{}, &reply)
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	// the pending entry is cleaned up
+	tr.mutex.Lock()
+	require.Empty(t, tr.pending)
+	tr.mutex.Unlock()
+
+	// the late response is discarded
+	time.Sleep(1500 * time.Millisecond)
+	require.Empty(t, reply)
+
+	var api int
+	require.NoError(t, tr.Call(3, "get_api", []interface{}{}, &api))
+	require.Equal(t, 3, api)
+}
+
+func TestTransport_Keepalive(t *testing.T) {
+	n := &node{}
+	server := httptest.NewServer(websocket.Handler(n.handle))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	t.Run("pings keep the idle connection", func(t *testing.T) {
+		tr, err := NewTransport(url, WithKeepalive(20*time.Millisecond), WithReadTimeout(100*time.Millisecond))
+		require.NoError(t, err)
+		defer tr.Close()
+
+		time.Sleep(300 * time.Millisecond)
+
+		var databaseID uint8
+		require.NoError(t, tr.Call(1, "database", []interface{}{}, &databaseID))
+		require.Equal(t, uint8(2), databaseID)
+	})
+
+	t.Run("read timeout", func(t *testing.T) {
+		tr, err := NewTransport(url, WithKeepalive(0), WithReadTimeout(100*time.Millisecond), WithoutReconnect())
+		require.NoError(t, err)
+		defer tr.Close()
+
+		var reply string
+		err = tr.Call(1, "slow", []interface{}{}, &reply)
+		require.Equal(t, ErrTimeout, err)
+		require.True(t, retry.Retryable(err))
+	})
+
+	t.Run("ping failure", func(t *testing.T) {
+		require.True(t, retry.Retryable(pingError(errors.New("use of closed network connection"))))
+	})
+}
+
+func TestTransport_NoticeTimeout(t *testing.T) {
+	n := &node{}
+	server := httptest.NewServer(websocket.Handler(n.handle))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	tr, err := NewTransport(url,
+		WithNoticeTimeout(100*time.Millisecond),
+		WithBackoff(Backoff{Min: 10 * time.Millisecond, Max: 10 * time.Millisecond}),
+	)
+	require.NoError(t, err)
+	defer tr.Close()
+
+	notices := make(chan json.RawMessage, 10)
+	require.NoError(t, tr.SetCallback(2, "set_block_applied_callback", func(raw json.RawMessage) {
+		notices <- raw
+	}))
+	<-notices
+
+	// the node sends a single notice per registration, so the connection is considered stale
+	// and the callback is registered again on a new connection
+	select {
+	case <-notices:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection has not been restored")
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	require.True(t, n.connections > 1)
+
+	// a timeout shorter than the check interval
+	short, err := NewTransport(url, WithNoticeTimeout(time.Nanosecond))
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, short.Close())
+}
+
+func TestTransport_BatchCall(t *testing.T) {
+	n := &node{}
+	server := httptest.NewServer(websocket.Handler(n.handle))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	tr, err := NewTransport(url)
+	require.NoError(t, err)
+	defer tr.Close()
+
+	var slow string
+	apis := make([]int, 5)
+	requests := []*caller.BatchRequest{{API: 1, Method: "slow", Args: caller.EmptyParams, Reply: &slow}}
+	for i := range apis {
+		requests = append(requests, &caller.BatchRequest{API: caller.APIID(i + 1), Method: "get_api", Args: caller.EmptyParams, Reply: &apis[i]})
+	}
+
+	require.NoError(t, caller.BatchCall(context.Background(), tr, requests))
+	require.Equal(t, "slow", slow)
+	require.Equal(t, []int{1, 2, 3, 4, 5}, apis)
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		requests := []*caller.BatchRequest{{API: 1, Method: "slow", Args: caller.EmptyParams}}
+		require.Equal(t, context.DeadlineExceeded, tr.BatchCall(ctx, requests))
+		require.Equal(t, context.DeadlineExceeded, requests[0].Err)
+	})
+}
+
+func TestTransport_Metrics(t *testing.T) {
+	n := &node{}
+	server := httptest.NewServer(websocket.Handler(n.handle))
+	defer server.Close()
+
+	collector := metrics.NewPrometheus()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	tr, err := NewTransport(url, WithMetrics(collector), WithBackoff(Backoff{Min: 10 * time.Millisecond, Max: 10 * time.Millisecond}))
+	require.NoError(t, err)
+	defer tr.Close()
+
+	var databaseID uint8
+	require.NoError(t, tr.Call(1, "database", []interface{}{}, &databaseID))
+	require.NoError(t, tr.Call(2, "get_api", []interface{}{}, nil))
+
+	notices := make(chan json.RawMessage, 10)
+	require.NoError(t, tr.SetCallback(2, "set_block_applied_callback", func(raw json.RawMessage) {
+		notices <- raw
+	}))
+	<-notices
+
+	n.drop()
+	<-notices
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		collector.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+		return recorder.Body.String()
+	}
+	require.Eventually(t, func() bool {
+		return strings.Contains(scrape(), "bitshares_rpc_reconnects_total 1\n")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	body := scrape()
+	require.Contains(t, body, `bitshares_rpc_requests_total{api="login",method="database"} 1`)
+	require.Contains(t, body, `bitshares_rpc_requests_total{api="database",method="get_api"} 1`)
+	require.Contains(t, body, `bitshares_rpc_notices_total{method="set_block_applied_callback"} 2`)
+	require.Contains(t, body, "bitshares_rpc_pending_calls 0\n")
+}
@@ -0,0 +1,146 @@
+package types
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/json"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/encoding/transaction"
+)
+
+// addressLength is the length of the RIPEMD-160 digest of an address
+const addressLength = 20
+
+// Address is a Graphene address: RIPEMD-160 of SHA-512 of the compressed public key.
+// It's rendered as the chain prefix followed by base58 of the digest and the first 4 bytes of its RIPEMD-160 digest.
+// Only the digest is serialized to the binary format, so a decoded address keeps its prefix, DefaultAddressPrefix if there was none.
+type Address struct {
+	data   [addressLength]byte
+	prefix string
+}
+
+// NewAddress returns the address of the public key with the same prefix
+func NewAddress(key PublicKey) Address {
+	digest := sha512.Sum512(key.Bytes())
+
+	address := Address{prefix: key.Prefix()}
+	copy(address.data[:], ripemd160Sum(digest[:]))
+	return address
+}
+
+// ParseAddress parses the address with any prefix
+func ParseAddress(s string) (Address, error) {
+	// the length of the base58 encoded digest and checksum varies, so the prefix is the shortest one
+	// followed by a valid digest
+	for i := 1; i < len(s); i++ {
+		decoded := base58.Decode(s[i:])
+		if len(decoded) != addressLength+4 {
+			continue
+		}
+		data, checksum := decoded[:addressLength], decoded[addressLength:]
+		if !bytes.Equal(checksum, ripemd160Checksum(data)) {
+			continue
+		}
+
+		address := Address{prefix: s[:i]}
+		copy(address.data[:], data)
+		return address, nil
+	}
+	return Address{}, errors.Errorf("invalid address %q", s)
+}
+
+// ParseAddressWithPrefix parses the address of the chain with the given prefix,
+// e.g. database.Config.GrapheneAddressPrefix, an address of another chain is rejected
+func ParseAddressWithPrefix(s, prefix string) (Address, error) {
+	// the prefix is known, so the shortest valid prefix is not guessed
+	if !strings.HasPrefix(s, prefix) || len(s) == len(prefix) {
+		return Address{}, errors.Errorf("address %q doesn't have prefix %q", s, prefix)
+	}
+	decoded := base58.Decode(s[len(prefix):])
+	if len(decoded) != addressLength+4 {
+		return Address{}, errors.Errorf("invalid address %q", s)
+	}
+	data, checksum := decoded[:addressLength], decoded[addressLength:]
+	if !bytes.Equal(checksum, ripemd160Checksum(data)) {
+		return Address{}, errors.Errorf("invalid address %q: checksum mismatch", s)
+	}
+
+	address := Address{prefix: prefix}
+	copy(address.data[:], data)
+	return address, nil
+}
+
+// MustParseAddress is ParseAddress panicking on an error
+func MustParseAddress(s string) Address {
+	address, err := ParseAddress(s)
+	if err != nil {
+		panic(err)
+	}
+	return address
+}
+
+// Prefix returns the chain prefix of the address
+func (a Address) Prefix() string {
+	if a.prefix == "" {
+		return DefaultAddressPrefix
+	}
+	return a.prefix
+}
+
+// WithPrefix returns the same address with the given chain prefix
+func (a Address) WithPrefix(prefix string) Address {
+	if prefix == "" {
+		prefix = DefaultAddressPrefix
+	}
+	return Address{data: a.data, prefix: prefix}
+}
+
+// Bytes returns the RIPEMD-160 digest
+func (a Address) Bytes() []byte {
+	return append([]byte{}, a.data[:]...)
+}
+
+// Equal reports whether the addresses are the same regardless of the prefixes
+func (a Address) Equal(other Address) bool {
+	return a.data == other.data
+}
+
+func (a Address) String() string {
+	return a.Prefix() + base58.Encode(append(a.Bytes(), ripemd160Checksum(a.data[:])...))
+}
+
+func (a Address) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+func (a *Address) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	address, err := ParseAddress(s)
+	if err != nil {
+		return err
+	}
+	*a = address
+	return nil
+}
+
+// MarshalTransaction implements transaction.TransactionMarshaller
+func (a Address) MarshalTransaction(encoder *transaction.Encoder) error {
+	return encoder.EncodeFixedBytes(a.data[:], addressLength)
+}
+
+// UnmarshalTransaction implements transaction.TransactionUnmarshaller
+func (a *Address) UnmarshalTransaction(decoder *transaction.Decoder) error {
+	data, err := decoder.ReadBytes(addressLength)
+	if err != nil {
+		return err
+	}
+	copy(a.data[:], data)
+	a.prefix = a.Prefix()
+	return nil
+}
@@ -0,0 +1,181 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/encoding/transaction"
+)
+
+// FutureExtensions is the extensions_type of the operations without extensions,
+// a set of void_t which is always empty
+type FutureExtensions struct{}
+
+func (FutureExtensions) MarshalJSON() ([]byte, error) {
+	return []byte("[]"), nil
+}
+
+func (*FutureExtensions) UnmarshalJSON(b []byte) error {
+	var extensions []json.RawMessage
+	if err := json.Unmarshal(b, &extensions); err != nil {
+		return err
+	}
+	if len(extensions) != 0 {
+		return errors.Errorf("unexpected extensions %s", b)
+	}
+	return nil
+}
+
+// MarshalTransaction implements transaction.TransactionMarshaller
+func (FutureExtensions) MarshalTransaction(encoder *transaction.Encoder) error {
+	return encoder.EncodeFlatSet([]transaction.Void{}, nil)
+}
+
+// UnmarshalTransaction implements transaction.TransactionUnmarshaller
+func (*FutureExtensions) UnmarshalTransaction(decoder *transaction.Decoder) error {
+	return decoder.DecodeVector(func(int) error {
+		return errors.New("unexpected extensions")
+	})
+}
+
+// LimitOrderCreateExtensions are the extensions of LimitOrderCreateOperation
+type LimitOrderCreateExtensions struct {
+	OnFill []LimitOrderAutoAction `json:"on_fill,omitempty"`
+}
+
+func (ext LimitOrderCreateExtensions) MarshalJSON() ([]byte, error) {
+	type extensions LimitOrderCreateExtensions
+	return marshalExtensions(extensions(ext), len(ext.OnFill) == 0)
+}
+
+func (ext *LimitOrderCreateExtensions) UnmarshalJSON(b []byte) error {
+	type extensions LimitOrderCreateExtensions
+	return unmarshalExtensions(b, (*extensions)(ext))
+}
+
+// MarshalTransaction implements transaction.TransactionMarshaller
+func (ext LimitOrderCreateExtensions) MarshalTransaction(encoder *transaction.Encoder) error {
+	return encoder.EncodeExtension(ext)
+}
+
+// UnmarshalTransaction implements transaction.TransactionUnmarshaller
+func (ext *LimitOrderCreateExtensions) UnmarshalTransaction(decoder *transaction.Decoder) error {
+	return decoder.DecodeExtension(ext)
+}
+
+// CallOrderUpdateExtensions are the extensions of CallOrderUpdateOperation
+type CallOrderUpdateExtensions struct {
+	// TargetCollateralRatio is the collateral ratio, in thousandths, a margin call stops selling the collateral at,
+	// so only a part of the position is closed
+	TargetCollateralRatio *uint16 `json:"target_collateral_ratio,omitempty"`
+}
+
+func (ext CallOrderUpdateExtensions) MarshalJSON() ([]byte, error) {
+	type extensions CallOrderUpdateExtensions
+	return marshalExtensions(extensions(ext), ext.TargetCollateralRatio == nil)
+}
+
+func (ext *CallOrderUpdateExtensions) UnmarshalJSON(b []byte) error {
+	type extensions CallOrderUpdateExtensions
+	return unmarshalExtensions(b, (*extensions)(ext))
+}
+
+// MarshalTransaction implements transaction.TransactionMarshaller
+func (ext CallOrderUpdateExtensions) MarshalTransaction(encoder *transaction.Encoder) error {
+	return encoder.EncodeExtension(ext)
+}
+
+// UnmarshalTransaction implements transaction.TransactionUnmarshaller
+func (ext *CallOrderUpdateExtensions) UnmarshalTransaction(decoder *transaction.Decoder) error {
+	return decoder.DecodeExtension(ext)
+}
+
+// LimitOrderAutoAction is an action executed when a limit order is filled,
+// the take profit order is the only action so far
+type LimitOrderAutoAction struct {
+	CreateTakeProfitOrder *CreateTakeProfitOrderAction
+}
+
+// createTakeProfitOrderActionTag is the index of the take profit action in the limit_order_auto_action static_variant
+const createTakeProfitOrderActionTag = 0
+
+func (action LimitOrderAutoAction) MarshalJSON() ([]byte, error) {
+	if action.CreateTakeProfitOrder == nil {
+		return nil, errors.New("no limit order auto action specified")
+	}
+	return json.Marshal([]interface{}{createTakeProfitOrderActionTag, action.CreateTakeProfitOrder})
+}
+
+func (action *LimitOrderAutoAction) UnmarshalJSON(b []byte) error {
+	var tag uint64
+	var takeProfit CreateTakeProfitOrderAction
+	tuple := []interface{}{&tag, &takeProfit}
+	if err := json.Unmarshal(b, &tuple); err != nil {
+		return err
+	}
+	if tag != createTakeProfitOrderActionTag {
+		return errors.Errorf("unknown limit order auto action %d", tag)
+	}
+	action.CreateTakeProfitOrder = &takeProfit
+	return nil
+}
+
+// MarshalTransaction implements transaction.TransactionMarshaller
+func (action LimitOrderAutoAction) MarshalTransaction(encoder *transaction.Encoder) error {
+	if action.CreateTakeProfitOrder == nil {
+		return errors.New("no limit order auto action specified")
+	}
+	return encoder.EncodeStaticVariant(createTakeProfitOrderActionTag, action.CreateTakeProfitOrder)
+}
+
+// UnmarshalTransaction implements transaction.TransactionUnmarshaller
+func (action *LimitOrderAutoAction) UnmarshalTransaction(decoder *transaction.Decoder) error {
+	tag, err := decoder.DecodeStaticVariant()
+	if err != nil {
+		return err
+	}
+	if tag != createTakeProfitOrderActionTag {
+		return errors.Errorf("unknown limit order auto action %d", tag)
+	}
+
+	dec := transaction.NewRollingDecoder(decoder)
+	takeProfit := CreateTakeProfitOrderAction{FeeAssetID: assetObjectID}
+	dec.Decode(&takeProfit.FeeAssetID)
+	dec.Decode(&takeProfit.SpreadPercent)
+	dec.Decode(&takeProfit.SizePercent)
+	dec.Decode(&takeProfit.ExpirationSeconds)
+	dec.Decode(&takeProfit.Repeat)
+	dec.Decode(&takeProfit.Extensions)
+	action.CreateTakeProfitOrder = &takeProfit
+	return dec.Err()
+}
+
+// CreateTakeProfitOrderAction creates an order on the opposite side once the limit order is filled.
+// The percents are in hundredths, 10000 is 100%.
+type CreateTakeProfitOrderAction struct {
+	FeeAssetID        ObjectID         `json:"fee_asset_id"`
+	SpreadPercent     uint16           `json:"spread_percent"`
+	SizePercent       uint16           `json:"size_percent"`
+	ExpirationSeconds uint32           `json:"expiration_seconds"`
+	Repeat            bool             `json:"repeat"`
+	Extensions        FutureExtensions `json:"extensions"`
+}
+
+// marshalExtensions marshals the extension<T> as an object of the present fields,
+// the empty extensions are marshalled as an empty array the nodes accepted before the extensions were introduced
+func marshalExtensions(v interface{}, empty bool) ([]byte, error) {
+	if empty {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(v)
+}
+
+// unmarshalExtensions unmarshals the extension<T> from an object or an empty array
+func unmarshalExtensions(b []byte, v interface{}) error {
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '[' {
+		var extensions FutureExtensions
+		return extensions.UnmarshalJSON(trimmed)
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,119 @@
+package types
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/scorum/bitshares-go/encoding/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitOrderCreateExtensions(t *testing.T) {
+	ext := LimitOrderCreateExtensions{
+		OnFill: []LimitOrderAutoAction{{
+			CreateTakeProfitOrder: &CreateTakeProfitOrderAction{
+				FeeAssetID:        MustParseObjectID("1.3.0"),
+				SpreadPercent:     100,
+				SizePercent:       10000,
+				ExpirationSeconds: 3600,
+				Repeat:            true,
+			},
+		}},
+	}
+
+	// JSON
+	data, err := json.Marshal(ext)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"on_fill":[[0,{
+		"fee_asset_id":"1.3.0",
+		"spread_percent":100,
+		"size_percent":10000,
+		"expiration_seconds":3600,
+		"repeat":true,
+		"extensions":[]
+	}]]}`, string(data))
+
+	var decodedJSON LimitOrderCreateExtensions
+	require.NoError(t, json.Unmarshal(data, &decodedJSON))
+	require.Equal(t, ext, decodedJSON)
+
+	// binary: one present field, on_fill with index 0, a vector of one take profit action
+	var b bytes.Buffer
+	require.NoError(t, transaction.NewEncoder(&b).Encode(ext))
+	require.Equal(t, "01"+"00"+"01"+"00"+"00"+"6400"+"1027"+"100e0000"+"01"+"00", hex.EncodeToString(b.Bytes()))
+
+	var decoded LimitOrderCreateExtensions
+	require.NoError(t, transaction.NewDecoder(bytes.NewReader(b.Bytes())).Decode(&decoded))
+	require.Equal(t, ext, decoded)
+}
+
+func TestLimitOrderCreateExtensions_Empty(t *testing.T) {
+	var ext LimitOrderCreateExtensions
+
+	data, err := json.Marshal(ext)
+	require.NoError(t, err)
+	require.Equal(t, "[]", string(data))
+
+	require.NoError(t, json.Unmarshal([]byte("[]"), &ext))
+	require.NoError(t, json.Unmarshal([]byte("{}"), &ext))
+	require.Error(t, json.Unmarshal([]byte("[[0, {}]]"), &ext))
+
+	var b bytes.Buffer
+	require.NoError(t, transaction.NewEncoder(&b).Encode(ext))
+	require.Equal(t, "00", hex.EncodeToString(b.Bytes()))
+}
+
+func TestFutureExtensions(t *testing.T) {
+	op := LimitOrderCancelOperation{}
+
+	data, err := json.Marshal(&op)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"extensions":[]`)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"extensions": []}`), &op))
+	require.Error(t, json.Unmarshal([]byte(`{"extensions": [[0, {}]]}`), &op))
+}
+
+func TestCallOrderUpdateOperation(t *testing.T) {
+	ratio := uint16(1750)
+	op := &CallOrderUpdateOperation{
+		Fee:             AssetAmount{AssetID: MustParseObjectID("1.3.0"), Amount: 100},
+		FundingAccount:  MustParseObjectID("1.2.1144"),
+		DeltaCollateral: AssetDelta{AssetID: MustParseObjectID("1.3.0"), Amount: 5000},
+		DeltaDebt:       AssetDelta{AssetID: MustParseObjectID("1.3.113"), Amount: -20},
+		Extensions:      CallOrderUpdateExtensions{TargetCollateralRatio: &ratio},
+	}
+
+	// JSON
+	data, err := json.Marshal(Operations{op})
+	require.NoError(t, err)
+	require.JSONEq(t, `[[3, {
+		"fee": {"amount": 100, "asset_id": "1.3.0"},
+		"funding_account": "1.2.1144",
+		"delta_collateral": {"amount": 5000, "asset_id": "1.3.0"},
+		"delta_debt": {"amount": -20, "asset_id": "1.3.113"},
+		"extensions": {"target_collateral_ratio": 1750}
+	}]]`, string(data))
+
+	var decodedJSON Operations
+	require.NoError(t, json.Unmarshal(data, &decodedJSON))
+	require.Equal(t, Operations{op}, decodedJSON)
+
+	// binary: the operation tag, the fee, the account, the deltas, then
+	// one present extension field, target_collateral_ratio with index 0
+	var b bytes.Buffer
+	require.NoError(t, transaction.NewEncoder(&b).Encode(Operations{op}))
+	require.Equal(t, "01"+"03"+"640000000000000000"+"f808"+"881300000000000000"+"ecffffffffffffff71"+"01"+"00"+"d606",
+		hex.EncodeToString(b.Bytes()))
+
+	var decoded Operations
+	require.NoError(t, transaction.NewDecoder(bytes.NewReader(b.Bytes())).Decode(&decoded))
+	require.Equal(t, Operations{op}, decoded)
+
+	// without the ratio
+	data, err = json.Marshal(CallOrderUpdateExtensions{})
+	require.NoError(t, err)
+	require.Equal(t, "[]", string(data))
+}
@@ -0,0 +1,170 @@
+package types
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil"
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/encoding/transaction"
+)
+
+// ErrMemoChecksum is returned by Decrypt when the message checksum doesn't match,
+// i.e. the memo was decrypted with a wrong key
+var ErrMemoChecksum = errors.New("memo checksum mismatch")
+
+// Memo is an encrypted message attached to a transfer. The message is encrypted with a key
+// derived from the shared secret of the sender and the recipient memo keys.
+type Memo struct {
+	From    PublicKey `json:"from"`
+	To      PublicKey `json:"to"`
+	Nonce   Suint64   `json:"nonce"`
+	Message string    `json:"message"`
+}
+
+// NewMemo encrypts the message with the sender memo key, given as WIF, for the recipient memo key.
+// The nonce has to be unique for the sender and the recipient, the wallets use a random number or a timestamp.
+func NewMemo(wif string, to PublicKey, nonce uint64, message string) (*Memo, error) {
+	privateKey, err := btcutil.DecodeWIF(wif)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode WIF")
+	}
+	if to.Key() == nil {
+		return nil, errors.New("empty recipient public key")
+	}
+
+	key, iv := memoCipherKey(privateKey.PrivKey, to.Key(), nonce)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// the message is prefixed with the checksum: the first 4 bytes of its SHA-256 digest
+	checksum := sha256.Sum256([]byte(message))
+	plaintext := pkcs7Pad(append(checksum[:4], message...), aes.BlockSize)
+
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, plaintext)
+
+	return &Memo{
+		From:    NewPublicKey(privateKey.PrivKey.PubKey(), to.Prefix()),
+		To:      to,
+		Nonce:   Suint64(nonce),
+		Message: hex.EncodeToString(ciphertext),
+	}, nil
+}
+
+// Decrypt decrypts the message with the memo key, given as WIF, of either the sender or the recipient
+func (memo *Memo) Decrypt(wif string) (string, error) {
+	privateKey, err := btcutil.DecodeWIF(wif)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode WIF")
+	}
+	if memo.From.Key() == nil || memo.To.Key() == nil {
+		return "", errors.New("empty memo public key")
+	}
+
+	// the shared secret is computed with the key of the other party
+	other := memo.To.Key()
+	if other.IsEqual(privateKey.PrivKey.PubKey()) {
+		other = memo.From.Key()
+	}
+
+	ciphertext, err := hex.DecodeString(memo.Message)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid memo message")
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return "", errors.New("invalid memo message length")
+	}
+
+	key, iv := memoCipherKey(privateKey.PrivKey, other, uint64(memo.Nonce))
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	plaintext, err = pkcs7Unpad(plaintext, aes.BlockSize)
+	if err != nil || len(plaintext) < 4 {
+		return "", ErrMemoChecksum
+	}
+
+	message := plaintext[4:]
+	checksum := sha256.Sum256(message)
+	if !bytes.Equal(checksum[:4], plaintext[:4]) {
+		return "", ErrMemoChecksum
+	}
+	return string(message), nil
+}
+
+// MarshalTransaction implements transaction.TransactionMarshaller
+func (memo *Memo) MarshalTransaction(encoder *transaction.Encoder) error {
+	message, err := hex.DecodeString(memo.Message)
+	if err != nil {
+		return errors.Wrap(err, "invalid memo message")
+	}
+
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.Encode(memo.From)
+	enc.Encode(memo.To)
+	enc.EncodeLittleEndianUInt64(uint64(memo.Nonce))
+	enc.EncodeBytes(message)
+	return enc.Err()
+}
+
+// UnmarshalTransaction implements transaction.TransactionUnmarshaller
+func (memo *Memo) UnmarshalTransaction(decoder *transaction.Decoder) error {
+	dec := transaction.NewRollingDecoder(decoder)
+	dec.Decode(&memo.From)
+	dec.Decode(&memo.To)
+	memo.Nonce = Suint64(dec.DecodeLittleEndianUInt64())
+	memo.Message = hex.EncodeToString(dec.DecodeBytes())
+	return dec.Err()
+}
+
+// memoCipherKey derives the AES-256 key and IV from the shared secret and the nonce the way Graphene does:
+// SHA-512 of the decimal nonce followed by the hex encoded SHA-512 of the shared point X coordinate
+func memoCipherKey(privateKey *btcec.PrivateKey, publicKey *btcec.PublicKey, nonce uint64) ([]byte, []byte) {
+	x, _ := btcec.S256().ScalarMult(publicKey.X, publicKey.Y, privateKey.D.Bytes())
+	secret := sha512.Sum512(paddedBytes(x.Bytes(), 32))
+
+	seed := sha512.Sum512([]byte(strconv.FormatUint(nonce, 10) + hex.EncodeToString(secret[:])))
+	return seed[:32], seed[32:48]
+}
+
+func paddedBytes(b []byte, n int) []byte {
+	if len(b) >= n {
+		return b
+	}
+	return append(make([]byte, n-len(b)), b...)
+}
+
+func pkcs7Pad(b []byte, blockSize int) []byte {
+	n := blockSize - len(b)%blockSize
+	return append(b, bytes.Repeat([]byte{byte(n)}, n)...)
+}
+
+func pkcs7Unpad(b []byte, blockSize int) ([]byte, error) {
+	if len(b) == 0 || len(b)%blockSize != 0 {
+		return nil, errors.New("invalid padding")
+	}
+	n := int(b[len(b)-1])
+	if n == 0 || n > blockSize || n > len(b) {
+		return nil, errors.New("invalid padding")
+	}
+	for _, c := range b[len(b)-n:] {
+		if int(c) != n {
+			return nil, errors.New("invalid padding")
+		}
+	}
+	return b[:len(b)-n], nil
+}
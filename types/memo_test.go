@@ -0,0 +1,80 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/scorum/bitshares-go/encoding/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	senderWIF    = "5JWHY5DxTF6qN5grTtChDCYBmWHfY9zaSsw4CxEKN5eZpH9iBma"
+	recipientWIF = "5KPipdRzoxrp6dDqsBfMD6oFZG356trVHV5QBGx3rABs1zzWWs8"
+	otherWIF     = "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"
+)
+
+func publicKeyOf(t *testing.T, wif string) PublicKey {
+	w, err := btcutil.DecodeWIF(wif)
+	require.NoError(t, err)
+	return NewPublicKey(w.PrivKey.PubKey(), "")
+}
+
+func TestMemo_Encrypt(t *testing.T) {
+	memo, err := NewMemo(senderWIF, publicKeyOf(t, recipientWIF), 5862723643998573708, "deposit 1.2.1144")
+	require.NoError(t, err)
+	require.Equal(t, publicKeyOf(t, senderWIF), memo.From)
+	require.Equal(t, publicKeyOf(t, recipientWIF), memo.To)
+
+	// both the sender and the recipient can read the message
+	message, err := memo.Decrypt(recipientWIF)
+	require.NoError(t, err)
+	require.Equal(t, "deposit 1.2.1144", message)
+
+	message, err = memo.Decrypt(senderWIF)
+	require.NoError(t, err)
+	require.Equal(t, "deposit 1.2.1144", message)
+
+	_, err = memo.Decrypt(otherWIF)
+	require.Equal(t, ErrMemoChecksum, err)
+
+	// the nonce is a part of the key
+	memo.Nonce++
+	_, err = memo.Decrypt(recipientWIF)
+	require.Equal(t, ErrMemoChecksum, err)
+}
+
+func TestMemo_Transaction(t *testing.T) {
+	memo, err := NewMemo(senderWIF, publicKeyOf(t, recipientWIF), 1, "deposit")
+	require.NoError(t, err)
+
+	op := NewTransferOperation(
+		MustParseObjectID("1.2.1144"),
+		MustParseObjectID("1.2.1145"),
+		AssetAmount{AssetID: MustParseObjectID("1.3.0"), Amount: 1000},
+		AssetAmount{AssetID: MustParseObjectID("1.3.0"), Amount: 2000},
+	)
+	op.Memo = memo
+
+	var b bytes.Buffer
+	require.NoError(t, transaction.NewEncoder(&b).Encode(Operations{op}))
+
+	var decoded Operations
+	require.NoError(t, transaction.NewDecoder(bytes.NewReader(b.Bytes())).Decode(&decoded))
+	require.Equal(t, Operations{op}, decoded)
+
+	message, err := decoded[0].(*TransferOperation).Memo.Decrypt(recipientWIF)
+	require.NoError(t, err)
+	require.Equal(t, "deposit", message)
+}
+
+func TestMemo_UnmarshalJSON(t *testing.T) {
+	var memo Memo
+	require.NoError(t, json.Unmarshal([]byte(`{"nonce": "5862723643998573708"}`), &memo))
+	require.Equal(t, Suint64(5862723643998573708), memo.Nonce)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"nonce": 42}`), &memo))
+	require.Equal(t, Suint64(42), memo.Nonce)
+}
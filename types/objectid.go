@@ -40,11 +40,43 @@ func (o *ObjectID) UnmarshalJSON(s []byte) error {
 	return nil
 }
 
+// Less implements transaction.Lesser, the object IDs are ordered like Graphene orders them:
+// by the space, then by the type and then by the instance
+func (o ObjectID) Less(other interface{}) bool {
+	id := other.(ObjectID)
+	if o.Space != id.Space {
+		return o.Space < id.Space
+	}
+	if o.Type != id.Type {
+		return o.Type < id.Type
+	}
+	return o.ID < id.ID
+}
+
 func (o ObjectID) MarshalTransaction(encoder *transaction.Encoder) error {
 	encoder.EncodeVarint(int64(o.ID))
 	return nil
 }
 
+// UnmarshalTransaction implements transaction.TransactionUnmarshaller.
+// Only the instance is serialized, the space and the type are kept as is,
+// so they have to be set by the enclosing type beforehand.
+func (o *ObjectID) UnmarshalTransaction(decoder *transaction.Decoder) error {
+	id, err := decoder.DecodeUVarint()
+	if err != nil {
+		return err
+	}
+	o.ID = id
+	return nil
+}
+
+// The object IDs spaces and types used by the decoders
+var (
+	accountObjectID    = ObjectID{Space: 1, Type: 2}
+	assetObjectID      = ObjectID{Space: 1, Type: 3}
+	limitOrderObjectID = ObjectID{Space: 1, Type: 7}
+)
+
 func MustParseObjectID(str string) ObjectID {
 	out, err := ParseObjectID(str)
 	if err != nil {
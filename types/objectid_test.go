@@ -0,0 +1,23 @@
+package types
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/scorum/bitshares-go/encoding/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectID_FlatSet(t *testing.T) {
+	// flat_set<account_id_type>{1.2.300, 1.2.200}: 200 is c801 and 300 is ac02,
+	// the set is ordered by the instances rather than by the serialized form
+	ids := []ObjectID{MustParseObjectID("1.2.300"), MustParseObjectID("1.2.200")}
+
+	var b bytes.Buffer
+	require.NoError(t, transaction.NewEncoder(&b).EncodeFlatSet(ids, nil))
+	require.Equal(t, "02c801ac02", hex.EncodeToString(b.Bytes()))
+
+	require.True(t, MustParseObjectID("1.2.300").Less(MustParseObjectID("1.3.0")))
+	require.False(t, MustParseObjectID("1.2.300").Less(MustParseObjectID("1.2.300")))
+}
@@ -70,6 +70,34 @@ func (ops Operations) MarshalJSON() ([]byte, error) {
 	return json.Marshal(tuples)
 }
 
+// MarshalTransaction implements transaction.TransactionMarshaller.
+// Every operation is encoded as a static_variant: the operation type followed by the operation fields.
+func (ops Operations) MarshalTransaction(encoder *transaction.Encoder) error {
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.EncodeUVarint(uint64(len(ops)))
+	for _, op := range ops {
+		if _, ok := op.(*UnknownOperation); ok {
+			return errors.Errorf("unknown operation %d can't be encoded", op.Type())
+		}
+		enc.EncodeUVarint(uint64(op.Type()))
+		enc.Encode(op)
+	}
+	return enc.Err()
+}
+
+// UnmarshalTransaction implements transaction.TransactionUnmarshaller
+func (ops *Operations) UnmarshalTransaction(decoder *transaction.Decoder) error {
+	*ops = nil
+	return decoder.DecodeVector(func(int) error {
+		op, err := decodeOperation(decoder)
+		if err != nil {
+			return err
+		}
+		*ops = append(*ops, op)
+		return nil
+	})
+}
+
 func unmarshalOperation(opType OpType, obj json.RawMessage) (Operation, error) {
 	op, ok := knownOperations[opType]
 	if !ok {
@@ -92,6 +120,35 @@ var knownOperations = map[OpType]reflect.Type{
 	TransferOpType:         reflect.TypeOf(TransferOperation{}),
 	LimitOrderCreateOpType: reflect.TypeOf(LimitOrderCreateOperation{}),
 	LimitOrderCancelOpType: reflect.TypeOf(LimitOrderCancelOperation{}),
+	CallOrderUpdateOpType:  reflect.TypeOf(CallOrderUpdateOperation{}),
+}
+
+// operationDecoder is an operation which can be decoded from the binary format.
+// The operation tag is decoded by the caller.
+type operationDecoder interface {
+	Operation
+	decodeFields(dec *transaction.RollingDecoder)
+}
+
+// decodeOperation decodes the operation tag and the operation
+func decodeOperation(decoder *transaction.Decoder) (Operation, error) {
+	tag, err := decoder.DecodeStaticVariant()
+	if err != nil {
+		return nil, err
+	}
+
+	t, ok := knownOperations[OpType(tag)]
+	if !ok {
+		return nil, errors.Errorf("operation %d can't be decoded", tag)
+	}
+	op, ok := reflect.New(t).Interface().(operationDecoder)
+	if !ok {
+		return nil, errors.Errorf("operation %d can't be decoded", tag)
+	}
+
+	dec := transaction.NewRollingDecoder(decoder)
+	op.decodeFields(dec)
+	return op, dec.Err()
 }
 
 // UnknownOperation
@@ -105,11 +162,10 @@ func (op *UnknownOperation) Type() OpType { return op.kind }
 // NewTransferOperation returns a new instance of TransferOperation
 func NewTransferOperation(from, to ObjectID, amount, fee AssetAmount) *TransferOperation {
 	op := &TransferOperation{
-		From:       from,
-		To:         to,
-		Amount:     amount,
-		Fee:        fee,
-		Extensions: []json.RawMessage{},
+		From:   from,
+		To:     to,
+		Amount: amount,
+		Fee:    fee,
 	}
 
 	return op
@@ -117,90 +173,98 @@ func NewTransferOperation(from, to ObjectID, amount, fee AssetAmount) *TransferO
 
 // TransferOperation
 type TransferOperation struct {
-	From       ObjectID          `json:"from"`
-	To         ObjectID          `json:"to"`
-	Amount     AssetAmount       `json:"amount"`
-	Fee        AssetAmount       `json:"fee"`
-	Memo       *Memo             `json:"memo,omitempty"`
-	Extensions []json.RawMessage `json:"extensions"`
-}
-
-type Memo struct {
-	From    string `json:"from"`
-	To      string `json:"to"`
-	Nonce   string `json:"nonce"`
-	Message string `json:"message"`
+	Fee        AssetAmount      `json:"fee"`
+	From       ObjectID         `json:"from"`
+	To         ObjectID         `json:"to"`
+	Amount     AssetAmount      `json:"amount"`
+	Memo       *Memo            `json:"memo,omitempty"`
+	Extensions FutureExtensions `json:"extensions"`
 }
 
 func (op *TransferOperation) Type() OpType { return TransferOpType }
 
-func (op *TransferOperation) MarshalTransaction(encoder *transaction.Encoder) error {
-	enc := transaction.NewRollingEncoder(encoder)
-	enc.EncodeUVarint(uint64(op.Type()))
-	enc.Encode(op.Fee)
-	enc.Encode(op.From)
-	enc.Encode(op.To)
-	enc.Encode(op.Amount)
-
-	//Memo?
-	enc.EncodeUVarint(0)
-	//Extensions
-	enc.EncodeUVarint(0)
-	return enc.Err()
+func (op *TransferOperation) decodeFields(dec *transaction.RollingDecoder) {
+	dec.Decode(&op.Fee)
+	op.From = accountObjectID
+	dec.Decode(&op.From)
+	op.To = accountObjectID
+	dec.Decode(&op.To)
+	dec.Decode(&op.Amount)
+
+	var memo Memo
+	if dec.DecodeOptional(&memo) {
+		op.Memo = &memo
+	}
+	dec.Decode(&op.Extensions)
 }
 
 // LimitOrderCreateOperation
 type LimitOrderCreateOperation struct {
-	Fee          AssetAmount       `json:"fee"`
-	Seller       ObjectID          `json:"seller"`
-	AmountToSell AssetAmount       `json:"amount_to_sell"`
-	MinToReceive AssetAmount       `json:"min_to_receive"`
-	Expiration   Time              `json:"expiration"`
-	FillOrKill   bool              `json:"fill_or_kill"`
-	Extensions   []json.RawMessage `json:"extensions"`
+	Fee          AssetAmount                `json:"fee"`
+	Seller       ObjectID                   `json:"seller"`
+	AmountToSell AssetAmount                `json:"amount_to_sell"`
+	MinToReceive AssetAmount                `json:"min_to_receive"`
+	Expiration   Time                       `json:"expiration"`
+	FillOrKill   bool                       `json:"fill_or_kill"`
+	Extensions   LimitOrderCreateExtensions `json:"extensions"`
 }
 
-func (op *LimitOrderCreateOperation) MarshalTransaction(encoder *transaction.Encoder) error {
-	enc := transaction.NewRollingEncoder(encoder)
-
-	enc.EncodeUVarint(uint64(op.Type()))
-	enc.Encode(op.Fee)
-	enc.Encode(op.Seller)
-	enc.Encode(op.AmountToSell)
-	enc.Encode(op.MinToReceive)
-	enc.Encode(op.Expiration)
-	enc.EncodeBool(op.FillOrKill)
+func (op *LimitOrderCreateOperation) decodeFields(dec *transaction.RollingDecoder) {
+	dec.Decode(&op.Fee)
+	op.Seller = accountObjectID
+	dec.Decode(&op.Seller)
+	dec.Decode(&op.AmountToSell)
+	dec.Decode(&op.MinToReceive)
+	dec.Decode(&op.Expiration)
+	op.FillOrKill = dec.DecodeBool()
 
-	//extensions
-	enc.EncodeUVarint(0)
-	return enc.Err()
+	dec.Decode(&op.Extensions)
 }
 
 func (op *LimitOrderCreateOperation) Type() OpType { return LimitOrderCreateOpType }
 
 // LimitOrderCancelOpType
 type LimitOrderCancelOperation struct {
-	Fee              AssetAmount       `json:"fee"`
-	FeePayingAccount ObjectID          `json:"fee_paying_account"`
-	Order            ObjectID          `json:"order"`
-	Extensions       []json.RawMessage `json:"extensions"`
+	Fee              AssetAmount      `json:"fee"`
+	FeePayingAccount ObjectID         `json:"fee_paying_account"`
+	Order            ObjectID         `json:"order"`
+	Extensions       FutureExtensions `json:"extensions"`
 }
 
-func (op *LimitOrderCancelOperation) MarshalTransaction(encoder *transaction.Encoder) error {
-	enc := transaction.NewRollingEncoder(encoder)
-
-	enc.EncodeUVarint(uint64(op.Type()))
-	enc.Encode(op.Fee)
-	enc.Encode(op.FeePayingAccount)
-	enc.Encode(op.Order)
+func (op *LimitOrderCancelOperation) decodeFields(dec *transaction.RollingDecoder) {
+	dec.Decode(&op.Fee)
+	op.FeePayingAccount = accountObjectID
+	dec.Decode(&op.FeePayingAccount)
+	op.Order = limitOrderObjectID
+	dec.Decode(&op.Order)
 
-	// extensions
-	enc.EncodeUVarint(0)
-	return enc.Err()
+	dec.Decode(&op.Extensions)
 }
 
 func (op *LimitOrderCancelOperation) Type() OpType { return LimitOrderCancelOpType }
 
+// CallOrderUpdateOperation changes the collateral and the debt of the call order of the funding account,
+// i.e. borrows a market pegged asset or repays it with the negative deltas
+type CallOrderUpdateOperation struct {
+	Fee             AssetAmount               `json:"fee"`
+	FundingAccount  ObjectID                  `json:"funding_account"`
+	DeltaCollateral AssetDelta                `json:"delta_collateral"`
+	DeltaDebt       AssetDelta                `json:"delta_debt"`
+	Extensions      CallOrderUpdateExtensions `json:"extensions"`
+}
+
+func (op *CallOrderUpdateOperation) decodeFields(dec *transaction.RollingDecoder) {
+	dec.Decode(&op.Fee)
+	op.FundingAccount = accountObjectID
+	dec.Decode(&op.FundingAccount)
+	dec.Decode(&op.DeltaCollateral)
+	dec.Decode(&op.DeltaDebt)
+
+	dec.Decode(&op.Extensions)
+}
+
+func (op *CallOrderUpdateOperation) Type() OpType { return CallOrderUpdateOpType }
+
 // FillOrderOpType
 type FillOrderOperation struct {
 	Order   ObjectID
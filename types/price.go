@@ -23,6 +23,15 @@ func (aa AssetAmount) MarshalTransaction(encoder *transaction.Encoder) error {
 	return enc.Err()
 }
 
+// UnmarshalTransaction implements transaction.TransactionUnmarshaller
+func (aa *AssetAmount) UnmarshalTransaction(decoder *transaction.Decoder) error {
+	dec := transaction.NewRollingDecoder(decoder)
+	aa.Amount = dec.DecodeLittleEndianUInt64()
+	aa.AssetID = assetObjectID
+	dec.Decode(&aa.AssetID)
+	return dec.Err()
+}
+
 // RPC client might return asset amount as uint64 or string,
 // therefore a custom unmarshaller is used
 func (aa *AssetAmount) UnmarshalJSON(b []byte) (err error) {
@@ -51,3 +60,45 @@ func (aa *AssetAmount) UnmarshalJSON(b []byte) (err error) {
 
 	return err
 }
+
+// AssetDelta is a signed amount of an asset, e.g. the change of the debt of a call order,
+// it has the same binary form as AssetAmount
+type AssetDelta struct {
+	Amount  int64    `json:"amount"`
+	AssetID ObjectID `json:"asset_id"`
+}
+
+// MarshalTransaction implements transaction.TransactionMarshaller
+func (ad AssetDelta) MarshalTransaction(encoder *transaction.Encoder) error {
+	enc := transaction.NewRollingEncoder(encoder)
+	enc.EncodeLittleEndianUInt64(uint64(ad.Amount))
+	enc.Encode(ad.AssetID)
+	return enc.Err()
+}
+
+// UnmarshalTransaction implements transaction.TransactionUnmarshaller
+func (ad *AssetDelta) UnmarshalTransaction(decoder *transaction.Decoder) error {
+	dec := transaction.NewRollingDecoder(decoder)
+	ad.Amount = int64(dec.DecodeLittleEndianUInt64())
+	ad.AssetID = assetObjectID
+	dec.Decode(&ad.AssetID)
+	return dec.Err()
+}
+
+// UnmarshalJSON accepts the amount as a number or a string like AssetAmount does
+func (ad *AssetDelta) UnmarshalJSON(b []byte) error {
+	var delta struct {
+		Amount  json.Number `json:"amount"`
+		AssetID ObjectID    `json:"asset_id"`
+	}
+	if err := json.Unmarshal(b, &delta); err != nil {
+		return err
+	}
+	amount, err := strconv.ParseInt(delta.Amount.String(), 10, 64)
+	if err != nil {
+		return err
+	}
+	ad.Amount = amount
+	ad.AssetID = delta.AssetID
+	return nil
+}
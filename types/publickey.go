@@ -0,0 +1,182 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/pkg/errors"
+	"github.com/scorum/bitshares-go/encoding/transaction"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// DefaultAddressPrefix is the prefix of the keys and the addresses on the BitShares mainnet.
+// The prefix of a chain is database.Config.GrapheneAddressPrefix.
+const DefaultAddressPrefix = "BTS"
+
+// publicKeyLength is the length of the base58 encoded key and checksum following the prefix,
+// a compressed key starts with 0x02 or 0x03 so it's always 50 characters
+const publicKeyLength = 50
+
+// PublicKey is a Graphene public key: the chain prefix followed by base58 of the compressed key
+// and the first 4 bytes of its RIPEMD-160 digest, e.g. BTS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV.
+// Only the key is serialized to the binary format, so a decoded key keeps its prefix, DefaultAddressPrefix if there was none.
+type PublicKey struct {
+	key    *btcec.PublicKey
+	prefix string
+}
+
+// NewPublicKey returns the public key with the given prefix, DefaultAddressPrefix is used when it's empty
+func NewPublicKey(key *btcec.PublicKey, prefix string) PublicKey {
+	if prefix == "" {
+		prefix = DefaultAddressPrefix
+	}
+	return PublicKey{key: key, prefix: prefix}
+}
+
+// ParsePublicKey parses the public key with any prefix
+func ParsePublicKey(s string) (PublicKey, error) {
+	if len(s) <= publicKeyLength {
+		return PublicKey{}, errors.Errorf("invalid public key %q", s)
+	}
+	prefix := s[:len(s)-publicKeyLength]
+
+	decoded := base58.Decode(s[len(prefix):])
+	if len(decoded) != btcec.PubKeyBytesLenCompressed+4 {
+		return PublicKey{}, errors.Errorf("invalid public key %q", s)
+	}
+
+	data, checksum := decoded[:btcec.PubKeyBytesLenCompressed], decoded[btcec.PubKeyBytesLenCompressed:]
+	if !bytes.Equal(checksum, ripemd160Checksum(data)) {
+		return PublicKey{}, errors.Errorf("invalid public key %q: checksum mismatch", s)
+	}
+
+	key, err := btcec.ParsePubKey(data, btcec.S256())
+	if err != nil {
+		return PublicKey{}, errors.Wrapf(err, "invalid public key %q", s)
+	}
+	return PublicKey{key: key, prefix: prefix}, nil
+}
+
+// ParsePublicKeyWithPrefix parses the public key of the chain with the given prefix,
+// e.g. database.Config.GrapheneAddressPrefix, a key of another chain is rejected
+func ParsePublicKeyWithPrefix(s, prefix string) (PublicKey, error) {
+	key, err := ParsePublicKey(s)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	if key.prefix != prefix {
+		return PublicKey{}, errors.Errorf("public key %q has prefix %q, expected %q", s, key.prefix, prefix)
+	}
+	return key, nil
+}
+
+// MustParsePublicKey is ParsePublicKey panicking on an error
+func MustParsePublicKey(s string) PublicKey {
+	key, err := ParsePublicKey(s)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// Key returns the secp256k1 public key, nil for the zero value
+func (pk PublicKey) Key() *btcec.PublicKey {
+	return pk.key
+}
+
+// Prefix returns the chain prefix of the key
+func (pk PublicKey) Prefix() string {
+	if pk.prefix == "" {
+		return DefaultAddressPrefix
+	}
+	return pk.prefix
+}
+
+// WithPrefix returns the same key with the given chain prefix
+func (pk PublicKey) WithPrefix(prefix string) PublicKey {
+	return NewPublicKey(pk.key, prefix)
+}
+
+// Bytes returns the compressed key
+func (pk PublicKey) Bytes() []byte {
+	if pk.key == nil {
+		return nil
+	}
+	return pk.key.SerializeCompressed()
+}
+
+// Equal reports whether the keys are the same regardless of the prefixes
+func (pk PublicKey) Equal(other PublicKey) bool {
+	if pk.key == nil || other.key == nil {
+		return pk.key == other.key
+	}
+	return pk.key.IsEqual(other.key)
+}
+
+// Address returns the address of the key with the same prefix
+func (pk PublicKey) Address() Address {
+	return NewAddress(pk)
+}
+
+func (pk PublicKey) String() string {
+	if pk.key == nil {
+		return ""
+	}
+	data := pk.key.SerializeCompressed()
+	return pk.Prefix() + base58.Encode(append(data, ripemd160Checksum(data)...))
+}
+
+func (pk PublicKey) MarshalJSON() ([]byte, error) {
+	if pk.key == nil {
+		return nil, errors.New("empty public key")
+	}
+	return json.Marshal(pk.String())
+}
+
+func (pk *PublicKey) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	key, err := ParsePublicKey(s)
+	if err != nil {
+		return err
+	}
+	*pk = key
+	return nil
+}
+
+// MarshalTransaction implements transaction.TransactionMarshaller
+func (pk PublicKey) MarshalTransaction(encoder *transaction.Encoder) error {
+	if pk.key == nil {
+		return errors.New("empty public key")
+	}
+	return encoder.EncodeFixedBytes(pk.key.SerializeCompressed(), btcec.PubKeyBytesLenCompressed)
+}
+
+// UnmarshalTransaction implements transaction.TransactionUnmarshaller
+func (pk *PublicKey) UnmarshalTransaction(decoder *transaction.Decoder) error {
+	data, err := decoder.ReadBytes(btcec.PubKeyBytesLenCompressed)
+	if err != nil {
+		return err
+	}
+	key, err := btcec.ParsePubKey(data, btcec.S256())
+	if err != nil {
+		return errors.Wrap(err, "invalid public key")
+	}
+	*pk = NewPublicKey(key, pk.prefix)
+	return nil
+}
+
+// ripemd160Checksum returns the first 4 bytes of the RIPEMD-160 digest of the data
+func ripemd160Checksum(data []byte) []byte {
+	return ripemd160Sum(data)[:4]
+}
+
+func ripemd160Sum(data []byte) []byte {
+	hasher := ripemd160.New()
+	hasher.Write(data)
+	return hasher.Sum(nil)
+}
@@ -0,0 +1,129 @@
+package types
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/scorum/bitshares-go/encoding/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+// the well known key of the Graphene genesis
+const genesisKey = "BTS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV"
+
+func TestPublicKey(t *testing.T) {
+	require.Equal(t, genesisKey, publicKeyOf(t, otherWIF).String())
+
+	key, err := ParsePublicKey(genesisKey)
+	require.NoError(t, err)
+	require.Equal(t, "BTS", key.Prefix())
+	require.True(t, key.Equal(publicKeyOf(t, otherWIF)))
+
+	testnet := key.WithPrefix("TEST")
+	require.Equal(t, "TEST6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV", testnet.String())
+	require.True(t, testnet.Equal(key))
+
+	parsed, err := ParsePublicKey(testnet.String())
+	require.NoError(t, err)
+	require.Equal(t, testnet, parsed)
+
+	for _, invalid := range []string{
+		"BTS6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CW",
+		"BTS",
+		"",
+	} {
+		_, err := ParsePublicKey(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestPublicKey_Marshal(t *testing.T) {
+	key := MustParsePublicKey(genesisKey).WithPrefix("TEST")
+
+	data, err := json.Marshal(key)
+	require.NoError(t, err)
+	require.Equal(t, `"TEST6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5GDW5CV"`, string(data))
+
+	var decodedJSON PublicKey
+	require.NoError(t, json.Unmarshal(data, &decodedJSON))
+	require.Equal(t, key, decodedJSON)
+
+	// the compressed key only
+	var b bytes.Buffer
+	require.NoError(t, transaction.NewEncoder(&b).Encode(key))
+	require.Equal(t, hex.EncodeToString(key.Bytes()), hex.EncodeToString(b.Bytes()))
+	require.Len(t, b.Bytes(), 33)
+
+	// the prefix is kept
+	decoded := PublicKey{}.WithPrefix("TEST")
+	require.NoError(t, transaction.NewDecoder(bytes.NewReader(b.Bytes())).Decode(&decoded))
+	require.Equal(t, key, decoded)
+
+	_, err = json.Marshal(PublicKey{})
+	require.Error(t, err)
+}
+
+func TestAddress(t *testing.T) {
+	key := MustParsePublicKey(genesisKey)
+	address := key.Address()
+	// the initial balance owner of the Graphene genesis
+	require.Equal(t, "BTSFAbAx7yuxt725qSZvfwWqkdCwp9ZnUama", address.String())
+	require.Equal(t, "BTS", address.Prefix())
+	require.Len(t, address.Bytes(), 20)
+
+	parsed, err := ParseAddress(address.String())
+	require.NoError(t, err)
+	require.Equal(t, address, parsed)
+
+	testnet := key.WithPrefix("TEST").Address()
+	require.True(t, testnet.Equal(address))
+	require.Equal(t, "TEST"+address.String()[3:], testnet.String())
+
+	parsed, err = ParseAddress(testnet.String())
+	require.NoError(t, err)
+	require.Equal(t, testnet, parsed)
+
+	_, err = ParseAddress(address.String()[:len(address.String())-1])
+	require.Error(t, err)
+
+	// JSON
+	data, err := json.Marshal(address)
+	require.NoError(t, err)
+	var decodedJSON Address
+	require.NoError(t, json.Unmarshal(data, &decodedJSON))
+	require.Equal(t, address, decodedJSON)
+
+	// binary: the digest only
+	var b bytes.Buffer
+	require.NoError(t, transaction.NewEncoder(&b).Encode(address))
+	require.Equal(t, address.Bytes(), b.Bytes())
+
+	var decoded Address
+	require.NoError(t, transaction.NewDecoder(bytes.NewReader(b.Bytes())).Decode(&decoded))
+	require.Equal(t, address, decoded)
+}
+
+func TestParseWithPrefix(t *testing.T) {
+	testnet := MustParsePublicKey(genesisKey).WithPrefix("TEST")
+
+	key, err := ParsePublicKeyWithPrefix(genesisKey, "BTS")
+	require.NoError(t, err)
+	require.Equal(t, genesisKey, key.String())
+
+	// a key of another chain
+	_, err = ParsePublicKeyWithPrefix(testnet.String(), "BTS")
+	require.Error(t, err)
+	_, err = ParsePublicKeyWithPrefix(genesisKey, "TEST")
+	require.Error(t, err)
+
+	address, err := ParseAddressWithPrefix(testnet.Address().String(), "TEST")
+	require.NoError(t, err)
+	require.Equal(t, testnet.Address(), address)
+
+	_, err = ParseAddressWithPrefix(testnet.Address().String(), "BTS")
+	require.Error(t, err)
+	_, err = ParseAddressWithPrefix("TEST", "TEST")
+	require.Error(t, err)
+}
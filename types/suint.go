@@ -16,20 +16,18 @@ type Suint32 uint32
 func (su *Suint64) UnmarshalJSON(b []byte) (err error) {
 	var u uint64
 	if err = json.Unmarshal(b, &u); err == nil {
-		temp := Suint64(u)
-		su = &temp
+		*su = Suint64(u)
 		return nil
 	}
 
 	// failed on uint64, try string
 	var s string
 	if err = json.Unmarshal(b, &s); err == nil {
-		u, err := strconv.Atoi(s)
+		u, err := strconv.ParseUint(s, 10, 64)
 		if err != nil {
 			return err
 		}
-		temp := Suint64(u)
-		su = &temp
+		*su = Suint64(u)
 		return nil
 	}
 
@@ -39,20 +37,18 @@ func (su *Suint64) UnmarshalJSON(b []byte) (err error) {
 func (su *Suint32) UnmarshalJSON(b []byte) (err error) {
 	var u uint32
 	if err = json.Unmarshal(b, &u); err == nil {
-		temp := Suint32(u)
-		su = &temp
+		*su = Suint32(u)
 		return nil
 	}
 
 	// failed on uint32, try string
 	var s string
 	if err = json.Unmarshal(b, &s); err == nil {
-		u, err := strconv.Atoi(s)
+		u, err := strconv.ParseUint(s, 10, 32)
 		if err != nil {
 			return err
 		}
-		temp := Suint32(u)
-		su = &temp
+		*su = Suint32(u)
 		return nil
 	}
 
@@ -33,3 +33,13 @@ func (t *Time) UnmarshalJSON(data []byte) error {
 func (t Time) MarshalTransaction(encoder *transaction.Encoder) error {
 	return encoder.EncodeLittleEndianUInt32(uint32(t.Time.UTC().Unix()))
 }
+
+// UnmarshalTransaction implements transaction.TransactionUnmarshaller
+func (t *Time) UnmarshalTransaction(decoder *transaction.Decoder) error {
+	seconds, err := decoder.DecodeLittleEndianUInt32()
+	if err != nil {
+		return err
+	}
+	*t = NewTime(time.Unix(int64(seconds), 0).UTC())
+	return nil
+}
@@ -1,6 +1,10 @@
 package types
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+
 	"github.com/pkg/errors"
 	"github.com/scorum/bitshares-go/encoding/transaction"
 )
@@ -25,17 +29,64 @@ func (tx *Transaction) MarshalTransaction(encoder *transaction.Encoder) error {
 	enc.Encode(tx.RefBlockPrefix)
 	enc.Encode(tx.Expiration)
 
-	enc.EncodeUVarint(uint64(len(tx.Operations)))
-	for _, op := range tx.Operations {
-		enc.Encode(op)
-	}
+	enc.Encode(tx.Operations)
 
-	// Extensions are not supported yet.
-	enc.EncodeUVarint(0)
+	enc.Encode(FutureExtensions{})
 	return enc.Err()
 }
 
+// UnmarshalTransaction implements transaction.TransactionUnmarshaller.
+// The signatures are not a part of the serialized transaction, see UnmarshalSignedTransaction.
+func (tx *Transaction) UnmarshalTransaction(decoder *transaction.Decoder) error {
+	dec := transaction.NewRollingDecoder(decoder)
+
+	dec.Decode(&tx.RefBlockNum)
+	dec.Decode(&tx.RefBlockPrefix)
+	dec.Decode(&tx.Expiration)
+
+	dec.Decode(&tx.Operations)
+
+	dec.Decode(&FutureExtensions{})
+	return dec.Err()
+}
+
+// UnmarshalSignedTransaction decodes a signed transaction, e.g. the result of get_transaction_hex:
+// the transaction is followed by the signatures
+func UnmarshalSignedTransaction(data []byte) (*Transaction, error) {
+	decoder := transaction.NewDecoder(bytes.NewReader(data))
+
+	var tx Transaction
+	if err := decoder.Decode(&tx); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the transaction")
+	}
+
+	tx.Signatures = []string{}
+	err := decoder.DecodeVector(func(int) error {
+		signature, err := decoder.ReadBytes(65)
+		if err != nil {
+			return err
+		}
+		tx.Signatures = append(tx.Signatures, hex.EncodeToString(signature))
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the signatures")
+	}
+	return &tx, nil
+}
+
 // PushOperation can be used to add an operation into the transaction.
 func (tx *Transaction) PushOperation(op Operation) {
 	tx.Operations = append(tx.Operations, op)
 }
+
+// ID returns the transaction ID, the first 20 bytes of the SHA-256 of the serialized transaction.
+// The signatures are not serialized, so the ID does not change once the transaction is signed.
+func (tx *Transaction) ID() (string, error) {
+	var b bytes.Buffer
+	if err := transaction.NewEncoder(&b).Encode(tx); err != nil {
+		return "", errors.Wrap(err, "failed to serialize the transaction")
+	}
+	digest := sha256.Sum256(b.Bytes())
+	return hex.EncodeToString(digest[:20]), nil
+}
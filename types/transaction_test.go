@@ -0,0 +1,142 @@
+package types
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/scorum/bitshares-go/encoding/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction_ID(t *testing.T) {
+	expiration := time.Date(2018, 6, 6, 10, 0, 0, 0, time.UTC)
+	tx := &Transaction{
+		RefBlockNum:    12345,
+		RefBlockPrefix: 2828765431,
+		Expiration:     NewTime(expiration),
+	}
+	_, err := tx.ID()
+	require.Error(t, err)
+
+	tx.PushOperation(NewTransferOperation(
+		MustParseObjectID("1.2.1144"),
+		MustParseObjectID("1.2.1145"),
+		AssetAmount{AssetID: MustParseObjectID("1.3.0"), Amount: 1000},
+		AssetAmount{AssetID: MustParseObjectID("1.3.0"), Amount: 2000},
+	))
+
+	// the transaction is serialized as
+	// ref_block_num 3930, ref_block_prefix f7889ba8, expiration a0b0175b, 1 operation:
+	// transfer 00, fee d007000000000000 00, from f808, to f908, amount e803000000000000 00,
+	// no memo 00, no extensions 00, then no transaction extensions 00
+	var b bytes.Buffer
+	require.NoError(t, transaction.NewEncoder(&b).Encode(tx))
+	require.Equal(t, "3930f7889ba8a0b0175b0100d00700000000000000f808f908e80300000000000000000000", hex.EncodeToString(b.Bytes()))
+
+	// the ID is the first 20 bytes of SHA-256 of the serialized transaction,
+	// the expected value is computed independently of this package
+	id, err := tx.ID()
+	require.NoError(t, err)
+	require.Equal(t, "46e95427848f97b37d4a15d035f8ca13eccaaf3c", id)
+
+	// the signatures are not a part of the ID
+	tx.Signatures = []string{"1f3b9c"}
+	signed, err := tx.ID()
+	require.NoError(t, err)
+	require.Equal(t, id, signed)
+}
+
+func TestTransaction_UnmarshalTransaction(t *testing.T) {
+	expiration := time.Date(2018, 6, 6, 10, 0, 0, 0, time.UTC)
+	tx := &Transaction{
+		RefBlockNum:    12345,
+		RefBlockPrefix: 2828765431,
+		Expiration:     NewTime(expiration),
+	}
+	tx.PushOperation(NewTransferOperation(
+		MustParseObjectID("1.2.1144"),
+		MustParseObjectID("1.2.1145"),
+		AssetAmount{AssetID: MustParseObjectID("1.3.0"), Amount: 1000},
+		AssetAmount{AssetID: MustParseObjectID("1.3.0"), Amount: 2000},
+	))
+	tx.PushOperation(&LimitOrderCreateOperation{
+		Fee:          AssetAmount{AssetID: MustParseObjectID("1.3.0"), Amount: 500},
+		Seller:       MustParseObjectID("1.2.1144"),
+		AmountToSell: AssetAmount{AssetID: MustParseObjectID("1.3.0"), Amount: 100},
+		MinToReceive: AssetAmount{AssetID: MustParseObjectID("1.3.1"), Amount: 1},
+		Expiration:   NewTime(expiration.Add(time.Hour)),
+		FillOrKill:   true,
+	})
+	tx.PushOperation(&LimitOrderCancelOperation{
+		Fee:              AssetAmount{AssetID: MustParseObjectID("1.3.0"), Amount: 50},
+		FeePayingAccount: MustParseObjectID("1.2.1144"),
+		Order:            MustParseObjectID("1.7.1032"),
+	})
+
+	var b bytes.Buffer
+	require.NoError(t, transaction.NewEncoder(&b).Encode(tx))
+	data := b.Bytes()
+
+	var decoded Transaction
+	require.NoError(t, transaction.NewDecoder(bytes.NewReader(data)).Decode(&decoded))
+	require.Equal(t, tx.Operations, decoded.Operations)
+	require.Equal(t, tx.RefBlockNum, decoded.RefBlockNum)
+	require.Equal(t, tx.RefBlockPrefix, decoded.RefBlockPrefix)
+	require.True(t, tx.Expiration.Equal(*decoded.Expiration.Time))
+
+	var again bytes.Buffer
+	require.NoError(t, transaction.NewEncoder(&again).Encode(&decoded))
+	require.Equal(t, data, again.Bytes())
+
+	// signed
+	signature := bytes.Repeat([]byte{0x1f}, 65)
+	signed := append(append([]byte{}, data...), 1)
+	signed = append(signed, signature...)
+
+	decodedSigned, err := UnmarshalSignedTransaction(signed)
+	require.NoError(t, err)
+	require.Equal(t, []string{hex.EncodeToString(signature)}, decodedSigned.Signatures)
+	require.Equal(t, tx.Operations, decodedSigned.Operations)
+
+	// truncated
+	_, err = UnmarshalSignedTransaction(data[:len(data)-3])
+	require.Error(t, err)
+}
+
+func TestOperations_MarshalTransaction(t *testing.T) {
+	ops := Operations{&LimitOrderCancelOperation{
+		Fee:              AssetAmount{AssetID: MustParseObjectID("1.3.0"), Amount: 50},
+		FeePayingAccount: MustParseObjectID("1.2.1144"),
+		Order:            MustParseObjectID("1.7.1032"),
+	}}
+
+	var b bytes.Buffer
+	require.NoError(t, transaction.NewEncoder(&b).Encode(ops))
+	require.Equal(t, "0102320000000000000000f808880800", hex.EncodeToString(b.Bytes()))
+
+	var decoded Operations
+	require.NoError(t, transaction.NewDecoder(bytes.NewReader(b.Bytes())).Decode(&decoded))
+	require.Equal(t, ops, decoded)
+
+	// a memo with invalid keys must not be silently dropped
+	transfer := NewTransferOperation(
+		MustParseObjectID("1.2.1144"),
+		MustParseObjectID("1.2.1145"),
+		AssetAmount{AssetID: MustParseObjectID("1.3.0"), Amount: 1000},
+		AssetAmount{AssetID: MustParseObjectID("1.3.0"), Amount: 2000},
+	)
+	transfer.Memo = &Memo{Message: "deposit"}
+	require.Error(t, transaction.NewEncoder(&b).Encode(Operations{transfer}))
+
+	require.Error(t, transaction.NewEncoder(&b).Encode(Operations{&UnknownOperation{kind: FillOrderOpType}}))
+}
+
+func TestUnmarshalSignedTransaction_Crafted(t *testing.T) {
+	// ref_block_num, ref_block_prefix and expiration followed by a huge number of operations
+	data, err := hex.DecodeString("3930f7889ba8a0b0175bffffffffffffffff7f00")
+	require.NoError(t, err)
+	_, err = UnmarshalSignedTransaction(data)
+	require.Error(t, err)
+}